@@ -0,0 +1,200 @@
+// This package converts the frequencies reported by the POTA (kHz strings)
+// and SOTA (MHz floats) APIs into Hz, maps them to the amateur bands of an
+// ITU region, and normalizes the free-form modes into canonical names and
+// families.
+package bandplan
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Frequency is a radio frequency in Hz.
+type Frequency int64
+
+// Region is an ITU region (1: Europe and Africa, 2: Americas, 3: Asia and
+// Oceania).
+type Region int
+
+// Band is an amateur band, with its edges in each ITU region. A zero edge
+// means the band isn't allocated in that region.
+type Band struct {
+	Name  string
+	Edges [3][2]Frequency
+}
+
+// MHz builds a Frequency from a value in MHz, as reported by SOTA.
+func MHz(mhz float64) Frequency {
+	return Frequency(math.Round(mhz * 1e6))
+}
+
+// ParseKHz parses a frequency in kHz, as reported by POTA (e.g. "14062.5").
+func ParseKHz(s string) (Frequency, error) {
+	khz, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid frequency %q: %w", s, err)
+	}
+	return Frequency(math.Round(khz * 1e3)), nil
+}
+
+// MHz returns the frequency in MHz.
+func (f Frequency) MHz() float64 {
+	return float64(f) / 1e6
+}
+
+// KHz returns the frequency in kHz.
+func (f Frequency) KHz() float64 {
+	return float64(f) / 1e3
+}
+
+// String formats the frequency in MHz, e.g. "14.062MHz".
+func (f Frequency) String() string {
+	return fmt.Sprintf("%.3fMHz", f.MHz())
+}
+
+func edges(lowMHz, highMHz float64) [2]Frequency {
+	return [2]Frequency{MHz(lowMHz), MHz(highMHz)}
+}
+
+// Bands lists the amateur bands from 160m to 70cm, with their edges in
+// regions 1, 2 and 3.
+var Bands = []Band{
+	{"160m", [3][2]Frequency{edges(1.81, 2.0), edges(1.8, 2.0), edges(1.8, 2.0)}},
+	{"80m", [3][2]Frequency{edges(3.5, 3.8), edges(3.5, 4.0), edges(3.5, 3.9)}},
+	{"60m", [3][2]Frequency{edges(5.3515, 5.3665), edges(5.3305, 5.4065), edges(5.3515, 5.3665)}},
+	{"40m", [3][2]Frequency{edges(7.0, 7.2), edges(7.0, 7.3), edges(7.0, 7.2)}},
+	{"30m", [3][2]Frequency{edges(10.1, 10.15), edges(10.1, 10.15), edges(10.1, 10.15)}},
+	{"20m", [3][2]Frequency{edges(14.0, 14.35), edges(14.0, 14.35), edges(14.0, 14.35)}},
+	{"17m", [3][2]Frequency{edges(18.068, 18.168), edges(18.068, 18.168), edges(18.068, 18.168)}},
+	{"15m", [3][2]Frequency{edges(21.0, 21.45), edges(21.0, 21.45), edges(21.0, 21.45)}},
+	{"12m", [3][2]Frequency{edges(24.89, 24.99), edges(24.89, 24.99), edges(24.89, 24.99)}},
+	{"10m", [3][2]Frequency{edges(28.0, 29.7), edges(28.0, 29.7), edges(28.0, 29.7)}},
+	{"6m", [3][2]Frequency{edges(50.0, 52.0), edges(50.0, 54.0), edges(50.0, 54.0)}},
+	{"4m", [3][2]Frequency{edges(70.0, 70.5), {}, {}}},
+	{"2m", [3][2]Frequency{edges(144.0, 146.0), edges(144.0, 148.0), edges(144.0, 148.0)}},
+	{"1.25m", [3][2]Frequency{{}, edges(222.0, 225.0), {}}},
+	{"70cm", [3][2]Frequency{edges(430.0, 440.0), edges(420.0, 450.0), edges(430.0, 440.0)}},
+}
+
+// In reports whether the frequency is within the band in the region.
+func (b Band) In(f Frequency, region Region) bool {
+	if region < 1 || region > 3 {
+		return false
+	}
+	e := b.Edges[region-1]
+	return e[1] != 0 && f >= e[0] && f <= e[1]
+}
+
+// span returns the lowest and highest edges of the band across all regions.
+func (b Band) span() (low, high Frequency) {
+	for _, e := range b.Edges {
+		if e[1] == 0 {
+			continue
+		}
+		if low == 0 || e[0] < low {
+			low = e[0]
+		}
+		if e[1] > high {
+			high = e[1]
+		}
+	}
+	return low, high
+}
+
+// outOfBandMargin is how far outside of a band's edges a frequency is still
+// considered a (probably mistyped or out of band) spot on that band.
+const outOfBandMargin = 0.05
+
+// Lookup returns the name of the band of the frequency and whether the
+// frequency is within that band's edges in the region. Frequencies slightly
+// outside of a band (e.g. 7.250MHz in region 1) return the band name with
+// inBand set to false. Frequencies far from any band return an empty name.
+func Lookup(f Frequency, region Region) (name string, inBand bool) {
+	for _, b := range Bands {
+		if b.In(f, region) {
+			return b.Name, true
+		}
+	}
+	for _, b := range Bands {
+		low, high := b.span()
+		margin := Frequency(float64(high-low) * outOfBandMargin)
+		if f >= low-margin && f <= high+margin {
+			return b.Name, false
+		}
+	}
+	return "", false
+}
+
+// Mode families.
+const (
+	CW    = "CW"
+	Phone = "PHONE"
+	Data  = "DATA"
+)
+
+// modeAliases maps the spelling variants found in spots to canonical modes.
+var modeAliases = map[string]string{
+	"CW":      "CW",
+	"SSB":     "SSB",
+	"USB":     "SSB",
+	"LSB":     "SSB",
+	"PHONE":   "SSB",
+	"AM":      "AM",
+	"FM":      "FM",
+	"NFM":     "FM",
+	"DV":      "DV",
+	"DSTAR":   "DV",
+	"DMR":     "DMR",
+	"C4FM":    "C4FM",
+	"FUSION":  "C4FM",
+	"FT8":     "FT8",
+	"FT4":     "FT4",
+	"JS8":     "JS8",
+	"JS8CALL": "JS8",
+	"PSK":     "PSK31",
+	"PSK31":   "PSK31",
+	"RTTY":    "RTTY",
+	"OLIVIA":  "OLIVIA",
+	"SSTV":    "SSTV",
+	"DATA":    "DATA",
+	"DIGI":    "DATA",
+	"DIGITAL": "DATA",
+}
+
+// modeFamilies maps the canonical modes to their family.
+var modeFamilies = map[string]string{
+	"CW":     CW,
+	"SSB":    Phone,
+	"AM":     Phone,
+	"FM":     Phone,
+	"DV":     Phone,
+	"DMR":    Phone,
+	"C4FM":   Phone,
+	"FT8":    Data,
+	"FT4":    Data,
+	"JS8":    Data,
+	"PSK31":  Data,
+	"RTTY":   Data,
+	"OLIVIA": Data,
+	"SSTV":   Data,
+	"DATA":   Data,
+}
+
+// NormalizeMode returns the canonical name of a mode, ignoring case, spaces
+// and dashes (e.g. "FT-8 " becomes "FT8" and "usb" becomes "SSB"). Unknown
+// modes are returned uppercase.
+func NormalizeMode(mode string) string {
+	key := strings.ToUpper(strings.NewReplacer(" ", "", "-", "", "_", "").Replace(mode))
+	if canonical, ok := modeAliases[key]; ok {
+		return canonical
+	}
+	return key
+}
+
+// ModeFamily returns the family of a mode (CW, PHONE or DATA), or an empty
+// string for unknown modes.
+func ModeFamily(mode string) string {
+	return modeFamilies[NormalizeMode(mode)]
+}
@@ -0,0 +1,90 @@
+package bandplan
+
+import "testing"
+
+func TestParseKHz(t *testing.T) {
+	tests := []struct {
+		input string
+		want  Frequency
+	}{
+		{"14062", 14062000},
+		{" 7032.5 ", 7032500},
+		{"146520", 146520000},
+	}
+	for _, tt := range tests {
+		got, err := ParseKHz(tt.input)
+		if err != nil || got != tt.want {
+			t.Errorf("ParseKHz(%q) = %d, %v, want %d", tt.input, got, err, tt.want)
+		}
+	}
+
+	if _, err := ParseKHz("twenty"); err == nil {
+		t.Error("ParseKHz should fail on invalid input")
+	}
+}
+
+func TestMHz(t *testing.T) {
+	// float32 values, as decoded from the SOTA API
+	if got := MHz(float64(float32(14.062))); got != 14062000 {
+		t.Errorf("MHz(14.062) = %d", got)
+	}
+	if got := Frequency(14062000).String(); got != "14.062MHz" {
+		t.Errorf("String() = %s", got)
+	}
+}
+
+func TestLookup(t *testing.T) {
+	tests := []struct {
+		name   string
+		f      Frequency
+		region Region
+		band   string
+		inBand bool
+	}{
+		{"20m", MHz(14.062), 2, "20m", true},
+		{"160m", MHz(1.840), 2, "160m", true},
+		{"70cm", MHz(446.0), 2, "70cm", true},
+		{"40m region 1 out of band", MHz(7.250), 1, "40m", false},
+		{"40m region 2", MHz(7.250), 2, "40m", true},
+		{"2m region 1 out of band", MHz(147.0), 1, "2m", false},
+		{"4m region 2 out of band", MHz(70.2), 2, "4m", false},
+		{"slightly below 20m", MHz(13.999), 2, "20m", false},
+		{"broadcast", MHz(9.6), 2, "", false},
+		{"unknown region", MHz(14.062), 0, "20m", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			band, inBand := Lookup(tt.f, tt.region)
+			if band != tt.band || inBand != tt.inBand {
+				t.Errorf("Lookup(%s, %d) = %q, %v, want %q, %v", tt.f, tt.region, band, inBand, tt.band, tt.inBand)
+			}
+		})
+	}
+}
+
+func TestNormalizeMode(t *testing.T) {
+	tests := []struct {
+		input  string
+		mode   string
+		family string
+	}{
+		{"cw", "CW", CW},
+		{"CW ", "CW", CW},
+		{"FT-8", "FT8", Data},
+		{"ft4", "FT4", Data},
+		{"USB", "SSB", Phone},
+		{"fm", "FM", Phone},
+		{"DATA", "DATA", Data},
+		{"digital", "DATA", Data},
+		{"Hell", "HELL", ""},
+		{"", "", ""},
+	}
+	for _, tt := range tests {
+		if got := NormalizeMode(tt.input); got != tt.mode {
+			t.Errorf("NormalizeMode(%q) = %q, want %q", tt.input, got, tt.mode)
+		}
+		if got := ModeFamily(tt.input); got != tt.family {
+			t.Errorf("ModeFamily(%q) = %q, want %q", tt.input, got, tt.family)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package bot
+
+import (
+	"log"
+	"sync"
+
+	"github.com/PAARA-org/PAARAbot/notify"
+	"github.com/bwmarrin/discordgo"
+)
+
+// AdminChannelID is the optional Discord channel receiving operational
+// messages such as roster refresh reports.
+var AdminChannelID string
+
+var (
+	adminSession *discordgo.Session
+	adminPending []string
+	adminMu      sync.Mutex
+)
+
+// PostAdmin sends a message to the admin channel. Messages posted before the
+// bot is connected are queued and sent once Run opens the Discord session.
+// If no admin channel is configured, the message is only logged.
+func PostAdmin(message string) {
+	log.Println("Admin:", message)
+	if AdminChannelID == "" {
+		return
+	}
+
+	adminMu.Lock()
+	defer adminMu.Unlock()
+	if adminSession == nil {
+		adminPending = append(adminPending, message)
+		return
+	}
+	sendAdmin(adminSession, message)
+}
+
+// setAdminSession records the open session and flushes any queued messages.
+func setAdminSession(s *discordgo.Session) {
+	adminMu.Lock()
+	defer adminMu.Unlock()
+	adminSession = s
+	if AdminChannelID == "" {
+		return
+	}
+	for _, message := range adminPending {
+		sendAdmin(s, message)
+	}
+	adminPending = nil
+}
+
+func sendAdmin(s *discordgo.Session, message string) {
+	if err := (Discord{s, AdminChannelID}).Notify(notify.Message{Title: "Admin", Text: message}); err != nil {
+		log.Println("Error sending admin message:", err)
+	}
+}
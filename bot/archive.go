@@ -0,0 +1,75 @@
+package bot
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/PAARA-org/PAARAbot/bandplan"
+	"github.com/PAARA-org/PAARAbot/hams"
+	"github.com/PAARA-org/PAARAbot/history"
+)
+
+// History archives the spots of the tracked callsigns.
+var History *history.Store
+
+// archive adds the spot to the history, dated by its spot time.
+func archive(spot Spot) {
+	t, err := parseSpotTime(spot.Time)
+	if err != nil {
+		t = time.Now()
+	}
+	_, err = History.Add(history.Entry{
+		ID:         spot.ID,
+		Time:       t.UTC(),
+		Program:    spot.Program,
+		Callsign:   spot.Callsign,
+		Reference:  spot.Reference,
+		References: spot.References,
+		Name:       spot.Name,
+		Hz:         int64(spot.Hz),
+		Band:       spot.Band,
+		// The statistics count the modes by their canonical name
+		Mode:     spot.NormalizedMode,
+		Spotter:  spot.Spotter,
+		Comments: spot.Comments,
+		Points:   spot.Points,
+	})
+	if err != nil {
+		fmt.Println("Error archiving spot:", err)
+	}
+}
+
+// activatorsAt returns the tracked callsigns spotted at the reference, the
+// most recent first.
+func activatorsAt(ref string) []string {
+	entries := History.Find(history.Query{Reference: ref})
+	var calls []string
+	for i := len(entries) - 1; i >= 0; i-- {
+		call := hams.BaseCall(entries[i].Callsign)
+		if !slices.Contains(calls, call) {
+			calls = append(calls, call)
+		}
+	}
+	return calls
+}
+
+// archivedSpots returns the 10 most recent archived spots of the callsign,
+// including its portable and foreign variants, e.g. KN6YUH/P.
+func archivedSpots(callsign string) []DisplaySpot {
+	entries := History.Find(history.Query{BaseCall: callsign})
+	var spots []DisplaySpot
+	for i := len(entries) - 1; i >= 0 && len(spots) < 10; i-- {
+		e := entries[i]
+		spots = append(spots, DisplaySpot{
+			ID:        e.ID,
+			Source:    e.Program,
+			Time:      e.Time.Local().Format("01/02 15:04"),
+			Location:  fmt.Sprintf("%s (%s)", strings.Join(e.Refs(), "/"), e.Name),
+			Frequency: bandplan.Frequency(e.Hz).String(),
+			Mode:      e.Mode,
+		})
+	}
+	return spots
+}
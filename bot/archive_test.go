@@ -0,0 +1,37 @@
+package bot
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/PAARA-org/PAARAbot/history"
+	"github.com/PAARA-org/PAARAbot/pota"
+	"github.com/PAARA-org/PAARAbot/sota"
+)
+
+func TestArchive(t *testing.T) {
+	History, _ = history.Open("")
+	defer func() { History = nil }()
+
+	archive(fromPota(pota.Spot{SpotID: 1, SpotTime: "2026-10-01T16:00:00", Activator: "KN6YUH/P", Reference: "US-4491", Comments: "2fer US-4816", Frequency: "14062", Mode: "CW", Name: "Superior National Forest"}))
+	archive(fromPota(pota.Spot{SpotID: 2, SpotTime: "2026-10-02T16:00:00", Activator: "AK6EU", Reference: "US-4491", Frequency: "7032", Mode: "CW"}))
+	archive(fromSota(sota.Spot{Id: 3, TimeStamp: "2026-10-03T16:00:00", ActivatorCallsign: "KN6YUH/P", SummitCode: "W6/CT-001", Frequency: 14.062, Mode: "CW"}))
+	// Spots are only archived once
+	archive(fromPota(pota.Spot{SpotID: 1, SpotTime: "2026-10-01T16:00:00", Activator: "KN6YUH/P", Reference: "US-4491", Frequency: "14062"}))
+
+	if got := activatorsAt("US-4491"); !slices.Equal(got, []string{"AK6EU", "KN6YUH"}) {
+		t.Errorf("activatorsAt(US-4491) = %v", got)
+	}
+	if got := activatorsAt("US-4816"); !slices.Equal(got, []string{"KN6YUH"}) {
+		t.Errorf("activatorsAt(US-4816) = %v", got)
+	}
+
+	spots := archivedSpots("KN6YUH/P")
+	if len(spots) != 2 || spots[0].ID != "SOTA-3" || spots[1].Location != "US-4491/US-4816 (Superior National Forest)" || spots[1].Frequency != "14.062MHz" {
+		t.Errorf("Unexpected archived spots: %+v", spots)
+	}
+	// The portable and foreign variants are found from the base callsign
+	if spots := archivedSpots("KN6YUH"); len(spots) != 2 {
+		t.Errorf("Unexpected archived spots of KN6YUH: %+v", spots)
+	}
+}
@@ -13,8 +13,11 @@ import (
 	"time"
 
 	"github.com/PAARA-org/PAARAbot/hams"
+	"github.com/PAARA-org/PAARAbot/history"
+	"github.com/PAARA-org/PAARAbot/plans"
 	"github.com/PAARA-org/PAARAbot/pota"
 	"github.com/PAARA-org/PAARAbot/sota"
+	"github.com/PAARA-org/PAARAbot/watch"
 	"github.com/bwmarrin/discordgo"
 )
 
@@ -35,8 +38,15 @@ func Run() {
 	logger := log.New(os.Stdout, "", log.Ldate|log.Ltime|log.Lshortfile)
 	logger.SetFlags(logger.Flags() | log.Llongfile)
 
-	// create a session
-	discord, err := discordgo.New("Bot " + BotToken)
+	// create a session. Without a token, the bot only posts through
+	// webhooks: it doesn't connect to the Discord gateway, and the commands
+	// are unavailable.
+	webhookOnly := BotToken == ""
+	token := "Bot " + BotToken
+	if webhookOnly {
+		token = ""
+	}
+	discord, err := discordgo.New(token)
 	if err != nil {
 		logger.Println("Error creating Discord session: ", err)
 		return
@@ -44,19 +54,51 @@ func Run() {
 
 	limiter := NewRateLimiter()
 
-	discord.AddHandler(messageHandler)
+	if Watchlists == nil {
+		Watchlists, _ = watch.Load("")
+	}
+	if History == nil {
+		History, _ = history.Open("")
+	}
+	if Plans == nil {
+		Plans, _ = plans.Load("")
+	}
 
-	// open session
-	err = discord.Open()
-	if err != nil {
-		logger.Println("Error opening connection:", err)
-		return
+	if !webhookOnly {
+		discord.AddHandler(messageHandler)
+		discord.AddHandler(commandHandler)
+		discord.AddHandler(registerCommands)
+
+		// open session
+		err = discord.Open()
+		if err != nil {
+			logger.Println("Error opening connection:", err)
+			return
+		}
+
+		defer discord.Close()
 	}
 
-	defer discord.Close()
+	setAdminSession(discord)
 
 	logger.Println("Bot running....")
 
+	var nextHuntersSummary time.Time
+	if HuntersChannelID != "" && HuntersSummaryTime != "" {
+		nextHuntersSummary, err = nextDaily(time.Now(), HuntersSummaryTime)
+		if err != nil {
+			logger.Println("Error scheduling the hunters summary:", err)
+			return
+		}
+	}
+
+	nextDigests := make([]time.Time, len(Digests))
+	for i, d := range Digests {
+		nextDigests[i] = d.Schedule.Next(time.Now())
+	}
+
+	var nextPlanCheck time.Time
+
 	// Start the message posting loop
 	ticker := time.NewTicker(RunInterval)
 	for range ticker.C {
@@ -80,7 +122,10 @@ func Run() {
 		currentCallSigns := hams.GetCallSigns()
 
 		// Go through the POTA spots and see if any of them is for a member callsign
+		var tracked []Spot
 		for _, v := range potaSpots {
+			notifyWatchers(discord, fromPota(v))
+			recordHunt(discord, fromPota(v), currentCallSigns)
 			if slices.Contains(currentCallSigns, v.Activator) {
 				updateCache(v.Activator, DisplaySpot{
 					ID:        fmt.Sprintf("POTA-%d", v.SpotID),
@@ -90,21 +135,13 @@ func Run() {
 					Frequency: v.Frequency,
 					Mode:      v.Mode,
 				})
-
-				activation := fmt.Sprintf("%s at %s (%s %s)", v.Activator, v.Reference, v.Name, v.LocationDesc)
-				message := fmt.Sprintf("%s at %s (%s %s) on %sKHz %s [%s] \n", v.Activator, v.Reference, v.Name, v.LocationDesc, v.Frequency, v.Mode, v.Comments)
-				if limiter.Allow(activation) {
-					_, err = discord.ChannelMessageSend(PotaChannelID, message)
-					if err != nil {
-						fmt.Println("Error sending message:", err)
-					}
-				} else {
-					fmt.Printf("Message throttled: %s\n", message)
-				}
+				tracked = append(tracked, fromPota(v))
 			}
 		}
 		// Do the same for the SOTA spots
 		for _, v := range sotaSpots {
+			notifyWatchers(discord, fromSota(v))
+			recordHunt(discord, fromSota(v), currentCallSigns)
 			if slices.Contains(currentCallSigns, v.ActivatorCallsign) {
 				updateCache(v.ActivatorCallsign, DisplaySpot{
 					ID:        fmt.Sprintf("SOTA-%d", v.Id),
@@ -114,26 +151,83 @@ func Run() {
 					Frequency: fmt.Sprintf("%.3fMHz", v.Frequency),
 					Mode:      v.Mode,
 				})
+				tracked = append(tracked, fromSota(v))
+			}
+		}
+
+		// Multi-park activations are posted once, listing all their references.
+		// First activations are found before archiving the spots.
+		merged := mergeActivations(tracked)
+		for i := range merged {
+			merged[i].Firsts = firsts(merged[i])
+		}
+		for _, spot := range tracked {
+			archive(spot)
+		}
+		tracked = merged
 
-				activation := fmt.Sprintf("%s at %s (%s - %dft)", v.ActivatorCallsign, v.SummitCode, v.SummitName, v.AltFt)
-				message := fmt.Sprintf("%s at %s (%s - %dft/%dm) on %.3fMHz %s [%s] \n", v.ActivatorCallsign, v.SummitCode, v.SummitName, v.AltFt, v.AltM, v.Frequency, v.Mode, v.Comments)
-				if limiter.Allow(activation) {
-					_, err = discord.ChannelMessageSend(SotaChannelID, message)
-					if err != nil {
-						fmt.Println("Error sending message:", err)
-					}
-					// If this SOTA peak is in a POTA park, let's log a message too!
-					r := sota.IsPota(v.SummitCode)
-					if r.IsPota {
-						message = fmt.Sprintf("%s at %s (%s) on %.3fMHz %s [from SOTA spot] \n", v.ActivatorCallsign, r.ParkId, r.ParkName, v.Frequency, v.Mode)
-						_, err = discord.ChannelMessageSend(PotaChannelID, message)
-						if err != nil {
-							fmt.Println("Error sending message:", err)
-						}
-					}
-				} else {
-					fmt.Printf("Message throttled: %s\n", message)
+		// Park-to-park and summit-to-summit contacts are posted as a single
+		// highlight, instead of one post per activation.
+		paired := make(map[string]bool)
+		for _, c := range findContacts(tracked) {
+			paired[c.A.ID], paired[c.B.ID] = true, true
+			// Consume the throttle of both activations, so they aren't posted
+			// separately in the next runs.
+			limiter.AllowAny(c.A.activationKeys())
+			limiter.AllowAny(c.B.activationKeys())
+			if limiter.Allow(c.key()) {
+				postContact(discord, c)
+			} else {
+				fmt.Printf("Message throttled: %s\n", c.key())
+			}
+		}
+
+		for _, spot := range tracked {
+			if paired[spot.ID] {
+				continue
+			}
+			// QSYs of activations with a thread or a forum post go there.
+			if followThread(discord, spot, time.Now()) {
+				continue
+			}
+			if !limiter.AllowAny(spot.activationKeys()) {
+				fmt.Printf("Message throttled: %s\n", spot.activation())
+				continue
+			}
+			messages, forumPosts := post(discord, spot)
+			startThreads(discord, spot, messages, forumPosts, time.Now())
+			linkPlan(discord, spot)
+			if spot.Program == "SOTA" {
+				postSotaInPota(discord, spot)
+			} else {
+				postPotaInSota(discord, spot)
+			}
+		}
+
+		closeStaleThreads(discord, time.Now())
+
+		if time.Now().After(nextPlanCheck) {
+			checkPlans(discord, time.Now())
+			nextPlanCheck = time.Now().Add(PlanCheckInterval)
+		} else {
+			remindPlans(discord, time.Now())
+		}
+
+		if !nextHuntersSummary.IsZero() && time.Now().After(nextHuntersSummary) {
+			postHuntersSummary(discord)
+			nextHuntersSummary, _ = nextDaily(time.Now(), HuntersSummaryTime)
+		}
+
+		// The other chat services get each digest once, rather than once
+		// per Discord channel. Its title tells its period and window.
+		notifiedDigests := make(map[string]bool)
+		for i, d := range Digests {
+			if !nextDigests[i].IsZero() && time.Now().After(nextDigests[i]) {
+				if m, ok := postDigest(discord, d, nextDigests[i]); ok && !notifiedDigests[m.Title] {
+					notifiedDigests[m.Title] = true
+					notifyAll(m)
 				}
+				nextDigests[i] = d.Schedule.Next(time.Now())
 			}
 		}
 
@@ -164,3 +258,26 @@ func (rl *RateLimiter) Allow(user string) bool {
 	}
 	return false
 }
+
+// AllowAny reports whether any of the keys is allowed, and records all of
+// them as used. This throttles an activation known under several keys as
+// a whole, while still allowing it when a new key shows up.
+func (rl *RateLimiter) AllowAny(keys []string) bool {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	now := time.Now()
+	allowed := false
+	for _, key := range keys {
+		lastAllowed, exists := rl.users[key]
+		if !exists || now.Sub(lastAllowed) >= ThrottleTime {
+			allowed = true
+		}
+	}
+	if allowed {
+		for _, key := range keys {
+			rl.users[key] = now
+		}
+	}
+	return allowed
+}
@@ -0,0 +1,254 @@
+package bot
+
+import (
+	"log"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// GuildID restricts the slash commands to a single Discord server, where
+// they are available immediately. Global commands can take up to an hour to
+// show up after being registered.
+var GuildID string
+
+// command is a slash command along with its handler.
+type command struct {
+	definition *discordgo.ApplicationCommand
+	handler    func(s *discordgo.Session, i *discordgo.InteractionCreate)
+}
+
+// commands lists the slash commands registered by the bot.
+var commands = []command{
+	{
+		definition: &discordgo.ApplicationCommand{
+			Name:        "watch",
+			Description: "Get a direct message when a callsign is spotted",
+			Options: []*discordgo.ApplicationCommandOption{
+				callSignOption,
+			},
+		},
+		handler: watchHandler,
+	},
+	{
+		definition: &discordgo.ApplicationCommand{
+			Name:        "unwatch",
+			Description: "Stop getting direct messages for a callsign",
+			Options: []*discordgo.ApplicationCommandOption{
+				callSignOption,
+			},
+		},
+		handler: unwatchHandler,
+	},
+	{
+		definition: &discordgo.ApplicationCommand{
+			Name:        "watchlist",
+			Description: "List your watched callsigns and notification settings",
+		},
+		handler: watchlistHandler,
+	},
+	{
+		definition: &discordgo.ApplicationCommand{
+			Name:        "watchsettings",
+			Description: "Set how often and when you get watchlist direct messages",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "throttle",
+					Description: "Minimum time between messages for the same activation, e.g. 2h",
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					Name:        "quiet_start",
+					Description: "Hour (0-23) when quiet hours start",
+					MinValue:    new(float64),
+					MaxValue:    23,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					Name:        "quiet_end",
+					Description: "Hour (0-23) when quiet hours end",
+					MinValue:    new(float64),
+					MaxValue:    23,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "timezone",
+					Description: "Timezone of the quiet hours, e.g. America/Los_Angeles",
+				},
+			},
+		},
+		handler: watchSettingsHandler,
+	},
+	{
+		definition: &discordgo.ApplicationCommand{
+			Name:        "subscribe",
+			Description: "Get mentioned on the spots matching a role, e.g. 20m-CW",
+			Options: []*discordgo.ApplicationCommandOption{
+				roleOption,
+			},
+		},
+		handler: subscribeHandler,
+	},
+	{
+		definition: &discordgo.ApplicationCommand{
+			Name:        "unsubscribe",
+			Description: "Stop getting mentioned on the spots matching a role",
+			Options: []*discordgo.ApplicationCommandOption{
+				roleOption,
+			},
+		},
+		handler: unsubscribeHandler,
+	},
+	{
+		definition: &discordgo.ApplicationCommand{
+			Name:        "summit",
+			Description: "Show the details of a SOTA summit",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "code",
+					Description: "Summit code, e.g. W6/CT-001",
+					Required:    true,
+				},
+			},
+		},
+		handler: summitHandler,
+	},
+	{
+		definition: &discordgo.ApplicationCommand{
+			Name:        "park",
+			Description: "Show the details of a POTA park",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "reference",
+					Description: "Park reference, e.g. US-4491",
+					Required:    true,
+				},
+			},
+		},
+		handler: parkHandler,
+	},
+	{
+		definition: &discordgo.ApplicationCommand{
+			Name:        "stats",
+			Description: "Show the activation statistics of a member, or of the club",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "callsign",
+					Description: "Member callsign, e.g. KN6YUH",
+				},
+				periodOption,
+			},
+		},
+		handler: statsHandler,
+	},
+	{
+		definition: &discordgo.ApplicationCommand{
+			Name:        "leaderboard",
+			Description: "Rank the members by activations",
+			Options: []*discordgo.ApplicationCommandOption{
+				periodOption,
+			},
+		},
+		handler: leaderboardHandler,
+	},
+}
+
+var callSignOption = &discordgo.ApplicationCommandOption{
+	Type:        discordgo.ApplicationCommandOptionString,
+	Name:        "callsign",
+	Description: "Ham callsign, e.g. KN6YUH",
+	Required:    true,
+}
+
+var roleOption = &discordgo.ApplicationCommandOption{
+	Type:        discordgo.ApplicationCommandOptionRole,
+	Name:        "role",
+	Description: "Spot role",
+	Required:    true,
+}
+
+// commandHandler dispatches the slash command interactions to their handler.
+func commandHandler(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	if i.Type != discordgo.InteractionApplicationCommand {
+		return
+	}
+	name := i.ApplicationCommandData().Name
+	for _, c := range commands {
+		if c.definition.Name == name {
+			c.handler(s, i)
+			return
+		}
+	}
+}
+
+// registerCommands creates (or updates) the slash commands once the session
+// is ready.
+func registerCommands(s *discordgo.Session, r *discordgo.Ready) {
+	var definitions []*discordgo.ApplicationCommand
+	for _, c := range commands {
+		definitions = append(definitions, c.definition)
+	}
+	if _, err := s.ApplicationCommandBulkOverwrite(r.User.ID, GuildID, definitions); err != nil {
+		log.Println("Error registering slash commands:", err)
+	}
+}
+
+// interactionUser returns the user who triggered the interaction, whether
+// it happened in a server or in a direct message.
+func interactionUser(i *discordgo.InteractionCreate) *discordgo.User {
+	if i.Member != nil {
+		return i.Member.User
+	}
+	return i.User
+}
+
+// optionValue returns the named option of a slash command, or nil.
+func optionValue(i *discordgo.InteractionCreate, name string) *discordgo.ApplicationCommandInteractionDataOption {
+	for _, o := range i.ApplicationCommandData().Options {
+		if o.Name == name {
+			return o
+		}
+	}
+	return nil
+}
+
+// respond replies to the interaction with a message only the user can see.
+func respond(s *discordgo.Session, i *discordgo.InteractionCreate, content string) {
+	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: content,
+			Flags:   discordgo.MessageFlagsEphemeral,
+		},
+	})
+	if err != nil {
+		log.Println("Error responding to interaction:", err)
+	}
+}
+
+// deferResponse acknowledges the interaction before a slow command, which
+// then replies with editResponse. Discord otherwise fails the interactions
+// not answered within 3 seconds.
+func deferResponse(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+	})
+	if err != nil {
+		log.Println("Error deferring interaction response:", err)
+	}
+}
+
+// editResponse replaces the deferred response with the content or the
+// embed, along with the attached files if any.
+func editResponse(s *discordgo.Session, i *discordgo.InteractionCreate, content string, embed *discordgo.MessageEmbed, files ...*discordgo.File) {
+	edit := &discordgo.WebhookEdit{Content: &content, Files: files}
+	if embed != nil {
+		edit.Embeds = &[]*discordgo.MessageEmbed{embed}
+	}
+	if _, err := s.InteractionResponseEdit(i.Interaction, edit); err != nil {
+		log.Println("Error editing interaction response:", err)
+	}
+}
@@ -0,0 +1,130 @@
+package bot
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/PAARA-org/PAARAbot/hams"
+	"github.com/PAARA-org/PAARAbot/notify"
+	"github.com/bwmarrin/discordgo"
+)
+
+// contactTolerance is how close (in Hz) the frequencies of two activators
+// must be for them to be considered in contact.
+const contactTolerance = 1000
+
+// contactWindow is how close the times of two spots on the same frequency
+// must be for the activators to be considered in contact, as the feeds keep
+// the spots of activators who are already QRT.
+const contactWindow = 10 * time.Minute
+
+// contact is a park-to-park, summit-to-summit or summit-to-park contact
+// between two tracked activators.
+type contact struct {
+	A, B Spot
+	// Spotted is true when one activator spotted the other, rather than both
+	// being found on the same frequency.
+	Spotted bool
+}
+
+// kind returns the contact type: P2P, S2S or S2P.
+func (c contact) kind() string {
+	switch {
+	case c.A.Program == "POTA" && c.B.Program == "POTA":
+		return "P2P"
+	case c.A.Program == "SOTA" && c.B.Program == "SOTA":
+		return "S2S"
+	default:
+		return "S2P"
+	}
+}
+
+// key identifies the contact, to throttle its posts.
+func (c contact) key() string {
+	pair := []string{c.A.activation(), c.B.activation()}
+	slices.Sort(pair)
+	return c.kind() + " " + strings.Join(pair, " & ")
+}
+
+// findContacts returns the contacts between the activations of the tracked
+// spots: either an activator spotted another active one, or both are on the
+// same frequency and mode family at the same time. Each spot is part of at
+// most one contact.
+func findContacts(spots []Spot) []contact {
+	var contacts []contact
+	used := make(map[string]bool)
+
+	for i, a := range spots {
+		for j, b := range spots {
+			if i == j || used[a.ID] || used[b.ID] || hams.BaseCall(a.Callsign) == hams.BaseCall(b.Callsign) || sharesReference(a, b) {
+				continue
+			}
+
+			spotted := hams.BaseCall(a.Spotter) == hams.BaseCall(b.Callsign)
+			sameFrequency := i < j && a.Hz != 0 && a.Family == b.Family &&
+				a.Hz-b.Hz <= contactTolerance && b.Hz-a.Hz <= contactTolerance && spottedTogether(a, b)
+			if !spotted && !sameFrequency {
+				continue
+			}
+
+			// When spotted, a was spotted by b
+			contacts = append(contacts, contact{A: a, B: b, Spotted: spotted})
+			used[a.ID], used[b.ID] = true, true
+		}
+	}
+	return contacts
+}
+
+// spottedTogether reports whether both spots are within contactWindow of
+// each other. Spots without a time are never together.
+func spottedTogether(a, b Spot) bool {
+	ta, errA := parseSpotTime(a.Time)
+	tb, errB := parseSpotTime(b.Time)
+	if errA != nil || errB != nil {
+		return false
+	}
+	return ta.Sub(tb) <= contactWindow && tb.Sub(ta) <= contactWindow
+}
+
+// sharesReference reports whether both activations are at a same reference.
+func sharesReference(a, b Spot) bool {
+	return slices.ContainsFunc(a.References, func(ref string) bool { return slices.Contains(b.References, ref) })
+}
+
+// contactMessage formats the highlight of a contact.
+func contactMessage(c contact) string {
+	how := "on the same frequency"
+	if c.Spotted {
+		how = fmt.Sprintf("spotted by %s", c.B.Callsign)
+	}
+	return fmt.Sprintf("🤝 **%s**: %s at %s (%s) and %s at %s (%s) on %s %s, %s\n",
+		c.kind(), c.A.Callsign, c.A.AllReferences(), c.A.Name, c.B.Callsign, c.B.AllReferences(), c.B.Name, c.A.Hz, c.A.Mode, how)
+}
+
+// postContact sends the highlight of a contact to the channels of both
+// activations.
+func postContact(discord *discordgo.Session, c contact) {
+	var channels []string
+	m := notify.Message{
+		Program: c.A.Program,
+		Title:   c.kind() + ": " + c.A.activation() + " and " + c.B.activation(),
+		Text:    contactMessage(c),
+		Tags:    []string{c.A.Program, c.B.Program, c.A.Band, c.A.NormalizedMode},
+	}
+	for _, spot := range []Spot{c.A, c.B} {
+		for _, r := range routesFor(spot.Callsign, spot.Program) {
+			if slices.Contains(channels, r.Channel) || !allowed(spot, r.Group) {
+				continue
+			}
+			channels = append(channels, r.Channel)
+			withMentions := m
+			withMentions.Text = mentions(c.A, c.B) + m.Text
+			if err := (Discord{discord, r.Channel}).Notify(withMentions); err != nil {
+				fmt.Println("Error sending message:", err)
+			}
+		}
+	}
+	notifyAll(m)
+}
@@ -0,0 +1,47 @@
+package bot
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/PAARA-org/PAARAbot/pota"
+	"github.com/PAARA-org/PAARAbot/sota"
+)
+
+func TestFindContacts(t *testing.T) {
+	spots := []Spot{
+		fromPota(pota.Spot{SpotID: 1, Activator: "KN6YUH", Reference: "US-0001", Frequency: "14062", Mode: "CW", Spotter: "AK6EU/P"}),
+		fromSota(sota.Spot{Id: 2, ActivatorCallsign: "AK6EU/P", SummitCode: "W6/CT-001", Frequency: 7.032, Mode: "cw", Callsign: "K6XYZ"}),
+		fromSota(sota.Spot{Id: 3, TimeStamp: "2026-10-19T16:00:00", ActivatorCallsign: "W6SOTA", SummitCode: "W6/CT-002", Frequency: 14.285, Mode: "SSB", Callsign: "W6SOTA"}),
+		fromSota(sota.Spot{Id: 4, TimeStamp: "2026-10-19T16:05:00", ActivatorCallsign: "N6HAM", SummitCode: "W6/CT-003", Frequency: 14.2855, Mode: "USB", Callsign: "K6XYZ"}),
+		fromPota(pota.Spot{SpotID: 5, SpotTime: "2026-10-19T16:00:00", Activator: "K6POTA", Reference: "US-0002", Frequency: "14285", Mode: "FT8", Spotter: "K6POTA"}),
+		// K6QRT was spotted on the same frequency, but 50 minutes earlier
+		fromPota(pota.Spot{SpotID: 6, SpotTime: "2026-10-19T15:10:00", Activator: "K6QRT", Reference: "US-0003", Frequency: "14285", Mode: "SSB", Spotter: "K6XYZ"}),
+	}
+
+	contacts := findContacts(spots)
+	if len(contacts) != 2 {
+		t.Fatalf("Expected 2 contacts, got %d: %+v", len(contacts), contacts)
+	}
+
+	// KN6YUH was spotted by AK6EU, who is activating a summit
+	if c := contacts[0]; c.A.ID != "POTA-1" || c.B.ID != "SOTA-2" || !c.Spotted || c.kind() != "S2P" {
+		t.Errorf("Unexpected spotted contact: %+v", c)
+	}
+	if msg := contactMessage(contacts[0]); !strings.Contains(msg, "spotted by AK6EU/P") {
+		t.Errorf("Unexpected message: %s", msg)
+	}
+
+	// W6SOTA and N6HAM are on the same frequency and mode family, K6POTA isn't
+	// using the same mode family.
+	if c := contacts[1]; c.A.ID != "SOTA-3" || c.B.ID != "SOTA-4" || c.Spotted || c.kind() != "S2S" {
+		t.Errorf("Unexpected frequency contact: %+v", c)
+	}
+
+	if contacts[0].key() == contacts[1].key() {
+		t.Error("Contacts should have different keys")
+	}
+	if stale := findContacts([]Spot{spots[2], spots[5]}); len(stale) != 0 {
+		t.Errorf("Spots 50 minutes apart shouldn't be a contact: %+v", stale)
+	}
+}
@@ -0,0 +1,172 @@
+package bot
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/PAARA-org/PAARAbot/hams"
+	"github.com/PAARA-org/PAARAbot/history"
+	"github.com/PAARA-org/PAARAbot/notify"
+	"github.com/PAARA-org/PAARAbot/schedule"
+	"github.com/PAARA-org/PAARAbot/stats"
+	"github.com/bwmarrin/discordgo"
+)
+
+// maxMessageLength is the maximum length of a Discord message.
+const maxMessageLength = 2000
+
+// defaultDigestSchedules are used for digests configured without a schedule.
+var defaultDigestSchedules = map[string]string{
+	"daily":  "0 21 * * *",
+	"weekly": "0 9 * * 1",
+}
+
+// Digest is a summary of the activations of the previous day or week,
+// posted to a channel on a schedule.
+type Digest struct {
+	Channel  string
+	Weekly   bool
+	Schedule schedule.Schedule
+}
+
+// Digests lists the digests to post.
+var Digests []Digest
+
+// ParseDigest parses a digest configuration: "channelID=daily" or
+// "channelID=weekly", optionally followed by "@" and a cron schedule in
+// local time, e.g. "1234=weekly@30 8 * * 1".
+func ParseDigest(value string) (Digest, error) {
+	channel, spec, found := strings.Cut(value, "=")
+	if !found || channel == "" {
+		return Digest{}, fmt.Errorf("expected channelID=daily or channelID=weekly, got %q", value)
+	}
+	period, expr, found := strings.Cut(spec, "@")
+	period = strings.ToLower(strings.TrimSpace(period))
+	if _, ok := defaultDigestSchedules[period]; !ok {
+		return Digest{}, fmt.Errorf("unknown digest period %q, expected daily or weekly", period)
+	}
+	if !found {
+		expr = defaultDigestSchedules[period]
+	}
+	s, err := schedule.Parse(expr)
+	if err != nil {
+		return Digest{}, err
+	}
+	return Digest{Channel: channel, Weekly: period == "weekly", Schedule: s}, nil
+}
+
+// window returns the period summarized by a digest posted at the given time.
+func (d Digest) window(at time.Time) (time.Time, time.Time) {
+	if d.Weekly {
+		return at.AddDate(0, 0, -7), at
+	}
+	return at.AddDate(0, 0, -1), at
+}
+
+// refsList formats an activation's references and bands, e.g.
+// "US-4491/US-4816 (20m, 40m CW)".
+func refsList(a stats.Activation) string {
+	return fmt.Sprintf("%s (%s %s)", strings.Join(a.References, "/"), strings.Join(a.Bands, ", "), strings.Join(a.Modes, ", "))
+}
+
+// digestMessage summarizes the entries of the window. The earlier entries
+// tell which references were activated for the first time.
+func digestMessage(title string, entries, earlier []history.Entry) string {
+	activations := stats.Activations(entries)
+	if len(activations) == 0 {
+		return ""
+	}
+
+	// References activated before, by each member and by anyone
+	byMember, byClub := make(map[string]bool), make(map[string]bool)
+	for _, e := range earlier {
+		for _, ref := range e.Refs() {
+			byMember[hams.BaseCall(e.Callsign)+" "+ref] = true
+			byClub[ref] = true
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("📰 **%s**\n", title))
+
+	sb.WriteString("\n**Activations**\n")
+	var calls []string
+	byCall := make(map[string][]stats.Activation)
+	for _, a := range activations {
+		if _, ok := byCall[a.Callsign]; !ok {
+			calls = append(calls, a.Callsign)
+		}
+		byCall[a.Callsign] = append(byCall[a.Callsign], a)
+	}
+	slices.Sort(calls)
+	for _, call := range calls {
+		var list []string
+		for _, a := range byCall[call] {
+			list = append(list, refsList(a))
+		}
+		sb.WriteString(fmt.Sprintf("- **%s**: %s\n", call, strings.Join(list, ", ")))
+	}
+
+	var firsts []string
+	for _, a := range activations {
+		for _, ref := range a.References {
+			key := a.Callsign + " " + ref
+			switch {
+			case !byClub[ref]:
+				firsts = append(firsts, fmt.Sprintf("- **%s**: %s, a first for the club\n", a.Callsign, ref))
+			case !byMember[key]:
+				firsts = append(firsts, fmt.Sprintf("- **%s**: %s\n", a.Callsign, ref))
+			default:
+				continue
+			}
+			byMember[key], byClub[ref] = true, true
+		}
+	}
+	if len(firsts) > 0 {
+		sb.WriteString("\n**First-time references**\n")
+		sb.WriteString(strings.Join(firsts, ""))
+	}
+
+	if contacts := stats.Contacts(entries); len(contacts) > 0 {
+		sb.WriteString("\n**Contacts**\n")
+		for _, c := range contacts {
+			sb.WriteString(fmt.Sprintf("- %s: %s at %s and %s at %s\n", c.Kind(),
+				c.A.Callsign, strings.Join(c.A.References, "/"), c.B.Callsign, strings.Join(c.B.References, "/")))
+		}
+	}
+
+	sb.WriteString("\n**Top activators**\n")
+	for rank, m := range stats.Summarize(activations) {
+		if rank == 3 {
+			break
+		}
+		sb.WriteString(fmt.Sprintf("%d. **%s**: %d activations, %d parks, %d summits\n", rank+1, m.Callsign, m.Activations, m.Parks, m.Summits))
+	}
+
+	message := sb.String()
+	if len(message) > maxMessageLength {
+		message = message[:strings.LastIndex(message[:maxMessageLength-2], "\n")] + "\n…"
+	}
+	return message
+}
+
+// postDigest posts the digest scheduled at the given time, unless there were
+// no activations. It returns the posted message, if any, for the Notifiers.
+func postDigest(discord *discordgo.Session, d Digest, at time.Time) (notify.Message, bool) {
+	from, to := d.window(at)
+	title := fmt.Sprintf("Daily digest, %s to %s", from.Format("01/02 15:04"), to.Format("01/02 15:04"))
+	if d.Weekly {
+		title = fmt.Sprintf("Weekly digest, %s to %s", from.Format("01/02"), to.Format("01/02"))
+	}
+	message := digestMessage(title, History.Find(history.Query{From: from, To: to}), History.Find(history.Query{To: from}))
+	if message == "" {
+		return notify.Message{}, false
+	}
+	m := notify.Message{Title: title, Text: message}
+	if err := (Discord{discord, d.Channel}).Notify(m); err != nil {
+		fmt.Println("Error sending message:", err)
+	}
+	return m, true
+}
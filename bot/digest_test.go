@@ -0,0 +1,55 @@
+package bot
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PAARA-org/PAARAbot/history"
+)
+
+func TestParseDigest(t *testing.T) {
+	d, err := ParseDigest("1234=weekly@30 8 * * 1")
+	if err != nil || d.Channel != "1234" || !d.Weekly || d.Schedule.String() != "30 8 * * 1" {
+		t.Errorf("ParseDigest() = %+v, %v", d, err)
+	}
+	d, err = ParseDigest("1234=Daily")
+	if err != nil || d.Weekly || d.Schedule.String() != defaultDigestSchedules["daily"] {
+		t.Errorf("ParseDigest() = %+v, %v", d, err)
+	}
+	for _, value := range []string{"1234", "=daily", "1234=monthly", "1234=daily@0 25 * * *"} {
+		if _, err := ParseDigest(value); err == nil {
+			t.Errorf("ParseDigest(%q) should fail", value)
+		}
+	}
+}
+
+func TestDigestMessage(t *testing.T) {
+	day := time.Date(2026, 10, 18, 16, 0, 0, 0, time.UTC)
+	earlier := []history.Entry{
+		{ID: "1", Time: day.AddDate(0, 0, -30), Program: "POTA", Callsign: "AK6EU", Reference: "US-4491"},
+	}
+	entries := []history.Entry{
+		{ID: "2", Time: day, Program: "POTA", Callsign: "KN6YUH", Reference: "US-4491", References: []string{"US-4491", "US-4816"}, Band: "20m", Mode: "CW"},
+		{ID: "3", Time: day.Add(5 * time.Minute), Program: "SOTA", Callsign: "AK6EU/P", Reference: "W6/CT-001", Band: "40m", Mode: "CW", Spotter: "KN6YUH"},
+	}
+
+	message := digestMessage("Daily digest", entries, earlier)
+	for _, want := range []string{
+		"📰 **Daily digest**",
+		"- **AK6EU**: W6/CT-001 (40m CW)",
+		"- **KN6YUH**: US-4491/US-4816 (20m CW)",
+		"- **KN6YUH**: US-4491\n",
+		"- **KN6YUH**: US-4816, a first for the club",
+		"- S2P: AK6EU at W6/CT-001 and KN6YUH at US-4491/US-4816",
+		"2. **KN6YUH**: 1 activations, 2 parks, 0 summits",
+	} {
+		if !strings.Contains(message, want) {
+			t.Errorf("Digest should contain %q:\n%s", want, message)
+		}
+	}
+
+	if digestMessage("Daily digest", nil, earlier) != "" {
+		t.Error("Digest without activations should be empty")
+	}
+}
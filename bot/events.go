@@ -0,0 +1,115 @@
+package bot
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/PAARA-org/PAARAbot/plans"
+	"github.com/bwmarrin/discordgo"
+)
+
+// ScheduledEvents turns the planned activations into Discord scheduled
+// events, so members get Discord's own reminders.
+var ScheduledEvents bool
+
+// Discord limits the length of the event fields.
+const (
+	maxEventName        = 100
+	maxEventLocation    = 100
+	maxEventDescription = 1000
+)
+
+// truncate shortens s to at most n characters.
+func truncate(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n-1]) + "…"
+}
+
+// eventParams describes the scheduled event of a plan. The location is the
+// name of the park or summit.
+func eventParams(p plans.Plan) *discordgo.GuildScheduledEventParams {
+	location := p.Reference
+	if p.Name != "" {
+		location = fmt.Sprintf("%s (%s)", p.Name, p.Reference)
+	}
+	var description []string
+	if p.Frequencies != "" {
+		description = append(description, "On "+p.Frequencies+".")
+	}
+	if p.Comments != "" {
+		description = append(description, p.Comments)
+	}
+	start, end := p.Start, p.End
+	return &discordgo.GuildScheduledEventParams{
+		Name:               truncate(fmt.Sprintf("%s %s activation of %s", p.Callsign, p.Program, p.Reference), maxEventName),
+		Description:        truncate(strings.Join(description, "\n"), maxEventDescription),
+		ScheduledStartTime: &start,
+		ScheduledEndTime:   &end,
+		PrivacyLevel:       discordgo.GuildScheduledEventPrivacyLevelGuildOnly,
+		EntityType:         discordgo.GuildScheduledEventEntityTypeExternal,
+		EntityMetadata:     &discordgo.GuildScheduledEventEntityMetadata{Location: truncate(location, maxEventLocation)},
+	}
+}
+
+// eventGuild returns the Discord server of the scheduled events of a plan:
+// the -guildID server, or the server of the activator's channel.
+func eventGuild(discord *discordgo.Session, p plans.Plan) string {
+	if GuildID != "" {
+		return GuildID
+	}
+	for _, r := range routesFor(p.Callsign, p.Program) {
+		channel, err := discord.State.Channel(r.Channel)
+		if err != nil {
+			channel, err = discord.Channel(r.Channel)
+		}
+		if err == nil && channel.GuildID != "" {
+			return channel.GuildID
+		}
+	}
+	return ""
+}
+
+// syncEvent creates or updates the scheduled event of a plan. Events can't
+// be scheduled in the past, so plans which already started are skipped.
+func syncEvent(discord *discordgo.Session, p plans.Plan, now time.Time) {
+	if !ScheduledEvents || !p.Start.After(now) {
+		return
+	}
+	guild := eventGuild(discord, p)
+	if guild == "" {
+		fmt.Println("Error scheduling event: no Discord server for", p.ID)
+		return
+	}
+	if p.EventID != "" {
+		if _, err := discord.GuildScheduledEventEdit(guild, p.EventID, eventParams(p)); err != nil {
+			fmt.Println("Error editing scheduled event:", err)
+		}
+		return
+	}
+	event, err := discord.GuildScheduledEventCreate(guild, eventParams(p))
+	if err != nil {
+		fmt.Println("Error creating scheduled event:", err)
+		return
+	}
+	if err := Plans.SetEvent(p.ID, event.ID); err != nil {
+		fmt.Println("Error saving plans:", err)
+	}
+}
+
+// setEventStatus starts or cancels the scheduled event of a plan.
+func setEventStatus(discord *discordgo.Session, p plans.Plan, status discordgo.GuildScheduledEventStatus) {
+	if !ScheduledEvents || p.EventID == "" {
+		return
+	}
+	guild := eventGuild(discord, p)
+	if guild == "" {
+		return
+	}
+	if _, err := discord.GuildScheduledEventEdit(guild, p.EventID, &discordgo.GuildScheduledEventParams{Status: status}); err != nil {
+		fmt.Println("Error editing scheduled event:", err)
+	}
+}
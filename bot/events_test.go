@@ -0,0 +1,31 @@
+package bot
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PAARA-org/PAARAbot/plans"
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestEventParams(t *testing.T) {
+	start := time.Date(2026, 10, 20, 16, 0, 0, 0, time.UTC)
+	p := plans.Plan{ID: "POTA-7", Program: "POTA", Callsign: "KN6YUH", Reference: "US-1234", Name: "Big Basin Redwoods State Park",
+		Start: start, End: start.Add(2 * time.Hour), Frequencies: "20m CW", Comments: "Evening"}
+	e := eventParams(p)
+	if e.Name != "KN6YUH POTA activation of US-1234" || e.EntityMetadata.Location != "Big Basin Redwoods State Park (US-1234)" {
+		t.Errorf("Unexpected event: %+v %+v", e, e.EntityMetadata)
+	}
+	if e.EntityType != discordgo.GuildScheduledEventEntityTypeExternal || !e.ScheduledStartTime.Equal(p.Start) || !e.ScheduledEndTime.Equal(p.End) {
+		t.Errorf("Unexpected event: %+v", e)
+	}
+	if e.Description != "On 20m CW.\nEvening" {
+		t.Errorf("Description = %q", e.Description)
+	}
+
+	p.Name = strings.Repeat("é", 120)
+	if got := []rune(eventParams(p).EntityMetadata.Location); len(got) != maxEventLocation || got[len(got)-1] != '…' {
+		t.Errorf("Location isn't truncated: %q", string(got))
+	}
+}
@@ -0,0 +1,71 @@
+package bot
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/PAARA-org/PAARAbot/hams"
+	"github.com/PAARA-org/PAARAbot/history"
+	"github.com/PAARA-org/PAARAbot/sota"
+	"github.com/bwmarrin/discordgo"
+)
+
+// celebrationColor is the color of the first activations' embeds.
+const celebrationColor = 0xF1C40F
+
+// firstsMinAge is how old the history must be for the club and member firsts
+// to be celebrated, as every reference is a first in a new history.
+const firstsMinAge = 30 * 24 * time.Hour
+
+// historyFirsts reports whether the history knows enough of the past
+// activations for the club and member firsts: it must be kept in a file
+// (-historyfile) and be at least firstsMinAge old.
+func historyFirsts() bool {
+	return History.Persistent() && History.Len() > 0 && time.Since(History.Oldest()) >= firstsMinAge
+}
+
+// firsts returns why the activation deserves a celebration, for each of its
+// references: a summit never activated before according to the summits list,
+// and never spotted in the history, a reference never activated by a club
+// member, or never by the activator, according to the history. It must be
+// called before archiving the spot.
+func firsts(spot Spot) []string {
+	call := hams.BaseCall(spot.Callsign)
+	var reasons []string
+	for _, ref := range spot.References {
+		if spot.Program == "SOTA" {
+			// The summits list is only updated daily
+			s, ok := sota.LookupSummit(ref)
+			if ok && s.ActivationCount == 0 && len(History.Find(history.Query{Reference: ref, Program: "SOTA"})) == 0 {
+				reasons = append(reasons, fmt.Sprintf("First ever activation of %s (%s)!", ref, s.Name))
+				continue
+			}
+		}
+		if !historyFirsts() {
+			continue
+		}
+		entries := History.Find(history.Query{Reference: ref, Program: spot.Program})
+		if len(entries) == 0 {
+			reasons = append(reasons, fmt.Sprintf("First activation of %s by a club member!", ref))
+			continue
+		}
+		own := false
+		for _, e := range entries {
+			own = own || hams.BaseCall(e.Callsign) == call
+		}
+		if !own {
+			reasons = append(reasons, fmt.Sprintf("%s's first activation of %s!", call, ref))
+		}
+	}
+	return reasons
+}
+
+// celebration is the embed celebrating the first activations of a spot.
+func celebration(spot Spot) *discordgo.MessageEmbed {
+	return &discordgo.MessageEmbed{
+		Title:       "🎉 " + spot.activation(),
+		Description: strings.Join(spot.Firsts, "\n"),
+		Color:       celebrationColor,
+	}
+}
@@ -0,0 +1,67 @@
+package bot
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/PAARA-org/PAARAbot/history"
+	"github.com/PAARA-org/PAARAbot/pota"
+	"github.com/PAARA-org/PAARAbot/sota"
+)
+
+func TestFirsts(t *testing.T) {
+	History, _ = history.Open("")
+	defer func() { History = nil }()
+
+	twoFer := fromPota(pota.Spot{SpotID: 1, Activator: "KN6YUH", Reference: "US-4491", Comments: "2fer US-4816", Frequency: "14062"})
+	if got := firsts(twoFer); len(got) != 0 {
+		t.Errorf("Nothing is a first without history: %v", got)
+	}
+
+	// Nor with a history only kept in memory, or too recent
+	old := fromPota(pota.Spot{SpotID: 2, Activator: "AK6EU", Reference: "US-4491", SpotTime: "2020-10-19T16:00:00", Frequency: "7032"})
+	archive(old)
+	if got := firsts(twoFer); len(got) != 0 {
+		t.Errorf("Nothing is a first without a history file: %v", got)
+	}
+	History, _ = history.Open(filepath.Join(t.TempDir(), "history.jsonl"))
+	defer History.Close()
+	archive(fromPota(pota.Spot{SpotID: 5, Activator: "AK6EU", Reference: "US-0001", Frequency: "7032"}))
+	if got := firsts(twoFer); len(got) != 0 {
+		t.Errorf("Nothing is a first with a recent history: %v", got)
+	}
+
+	archive(old)
+	want := []string{"KN6YUH's first activation of US-4491!", "First activation of US-4816 by a club member!"}
+	if got := firsts(twoFer); !slices.Equal(got, want) {
+		t.Errorf("firsts() = %v, want %v", got, want)
+	}
+
+	archive(twoFer)
+	if got := firsts(fromPota(pota.Spot{SpotID: 3, Activator: "KN6YUH/P", Reference: "US-4816", Frequency: "14062"})); len(got) != 0 {
+		t.Errorf("Second activation isn't a first: %v", got)
+	}
+
+	list, _ := sota.ParseSummits(strings.NewReader(`SOTA Summits List (Date=19/10/2026)
+SummitCode,AssociationName,RegionName,SummitName,AltM,AltFt,GridRef1,GridRef2,Longitude,Latitude,Points,BonusPoints,ValidFrom,ValidTo,ActivationCount,ActivationDate,ActivationCall
+W6/CT-002,USA - California,Central Coast,Loma Prieta,1154,3786,-121.8439,37.1114,-121.8439,37.1114,4,0,01/07/2010,31/12/2099,0,,
+`))
+	sota.SetSummits(list)
+	defer sota.SetSummits(make(map[string]sota.Summit))
+	summit := fromSota(sota.Spot{Id: 4, ActivatorCallsign: "KN6YUH", SummitCode: "W6/CT-002", Frequency: 14.062})
+	if got := firsts(summit); !slices.Equal(got, []string{"First ever activation of W6/CT-002 (Loma Prieta)!"}) {
+		t.Errorf("firsts() = %v", got)
+	}
+	summit.Firsts = firsts(summit)
+	if e := celebration(summit); e.Color != celebrationColor || !strings.HasPrefix(e.Title, "🎉 KN6YUH at W6/CT-002") {
+		t.Errorf("Unexpected celebration: %+v", e)
+	}
+
+	// The summit was activated since the summits list was updated
+	archive(summit)
+	if got := firsts(summit); len(got) != 0 {
+		t.Errorf("Summit activated since the list update isn't a first: %v", got)
+	}
+}
@@ -0,0 +1,82 @@
+package bot
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// maxForumTags is the maximum number of tags of a forum post.
+const maxForumTags = 5
+
+// forumChannel returns the channel if it's a forum channel, or nil.
+func forumChannel(discord *discordgo.Session, channelID string) *discordgo.Channel {
+	if isWebhook(channelID) {
+		return nil
+	}
+	channel, err := discord.State.Channel(channelID)
+	if err != nil {
+		if channel, err = discord.Channel(channelID); err != nil {
+			return nil
+		}
+	}
+	if channel.Type != discordgo.ChannelTypeGuildForum {
+		return nil
+	}
+	return channel
+}
+
+// spotTags returns the names of the forum tags of a spot: its program, band
+// and mode.
+func spotTags(spot Spot) []string {
+	return []string{spot.Program, spot.Band, spot.NormalizedMode}
+}
+
+// forumTags returns the IDs of the tags of the forum with the given names,
+// ignoring case. Names without a tag in the forum are skipped.
+func forumTags(forum *discordgo.Channel, names []string) []string {
+	var ids []string
+	for _, name := range names {
+		if name == "" {
+			continue
+		}
+		i := slices.IndexFunc(forum.AvailableTags, func(tag discordgo.ForumTag) bool { return strings.EqualFold(tag.Name, name) })
+		if i < 0 || slices.Contains(ids, forum.AvailableTags[i].ID) {
+			continue
+		}
+		ids = append(ids, forum.AvailableTags[i].ID)
+		if len(ids) == maxForumTags {
+			break
+		}
+	}
+	return ids
+}
+
+// send sends a message to a channel, given by ID or as a webhook URL. The
+// tags start with the program of the message, if any, which picks the
+// identity of the webhook posts. In a forum channel, the message starts a
+// new post with the title and tags; the returned message is then the first
+// message of the post, whose ID is also the ID of the post.
+func send(discord *discordgo.Session, channelID, title string, tags []string, data *discordgo.MessageSend) (*discordgo.Message, error) {
+	if isWebhook(channelID) {
+		program := ""
+		if len(tags) > 0 {
+			program = tags[0]
+		}
+		return executeWebhook(discord, channelID, program, data)
+	}
+	forum := forumChannel(discord, channelID)
+	if forum == nil {
+		return discord.ChannelMessageSendComplex(channelID, data)
+	}
+	thread, err := discord.ForumThreadStartComplex(channelID, &discordgo.ThreadStart{
+		Name:                truncate(title, maxThreadName),
+		AutoArchiveDuration: threadArchiveMinutes,
+		AppliedTags:         forumTags(forum, tags),
+	}, data)
+	if err != nil {
+		return nil, err
+	}
+	return &discordgo.Message{ID: thread.ID, ChannelID: thread.ID, Content: data.Content}, nil
+}
@@ -0,0 +1,32 @@
+package bot
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/PAARA-org/PAARAbot/pota"
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestForumTags(t *testing.T) {
+	forum := &discordgo.Channel{Type: discordgo.ChannelTypeGuildForum, AvailableTags: []discordgo.ForumTag{
+		{ID: "1", Name: "POTA"},
+		{ID: "2", Name: "SOTA"},
+		{ID: "3", Name: "WWFF"},
+		{ID: "4", Name: "20m"},
+		{ID: "5", Name: "40m"},
+		{ID: "6", Name: "cw"},
+	}}
+	spot := fromPota(pota.Spot{Activator: "KN6YUH", Reference: "US-4491", Frequency: "14062", Mode: "CW"})
+	if got := forumTags(forum, spotTags(spot)); !slices.Equal(got, []string{"1", "4", "6"}) {
+		t.Errorf("forumTags() = %v", got)
+	}
+	// Tags missing from the forum are skipped
+	spot = fromPota(pota.Spot{Activator: "KN6YUH", Reference: "US-4491", Frequency: "7074", Mode: "FT8"})
+	if got := forumTags(forum, spotTags(spot)); !slices.Equal(got, []string{"1", "5"}) {
+		t.Errorf("forumTags() = %v", got)
+	}
+	if got := forumTags(forum, []string{"POTA", "SOTA", "WWFF", "20m", "40m", "CW"}); len(got) != maxForumTags {
+		t.Errorf("forumTags() = %v, want at most %d tags", got, maxForumTags)
+	}
+}
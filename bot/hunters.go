@@ -0,0 +1,173 @@
+package bot
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/PAARA-org/PAARAbot/hams"
+	"github.com/PAARA-org/PAARAbot/notify"
+	"github.com/bwmarrin/discordgo"
+)
+
+// HuntersChannelID is the optional channel of the hunters feed, listing the
+// parks and summits the tracked callsigns have been chasing.
+var HuntersChannelID string
+
+// HuntersSummaryTime is the local time ("15:04") of the daily hunters
+// summary. When empty, each hunt is posted as soon as it's spotted.
+var HuntersSummaryTime string
+
+// hunt is a tracked callsign spotting an activator, i.e. chasing them.
+type hunt struct {
+	Hunter    string
+	Activator string
+	Program   string
+	Reference string
+	Name      string
+	Frequency string
+	Mode      string
+	Time      time.Time
+}
+
+// key identifies a hunt, so it's only recorded once.
+func (h hunt) key() string {
+	return strings.Join([]string{h.Hunter, h.Activator, h.Reference, h.Frequency}, "|")
+}
+
+// huntLog records the hunts seen since the last summary.
+type huntLog struct {
+	mu    sync.Mutex
+	seen  map[string]time.Time
+	hunts []hunt
+}
+
+var hunts = &huntLog{seen: make(map[string]time.Time)}
+
+// record returns false if the hunt was already seen within the throttle
+// time. New hunts are kept for the summary when keep is set.
+func (l *huntLog) record(h hunt, keep bool) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for key, last := range l.seen {
+		if h.Time.Sub(last) >= ThrottleTime {
+			delete(l.seen, key)
+		}
+	}
+	if _, exists := l.seen[h.key()]; exists {
+		return false
+	}
+	l.seen[h.key()] = h.Time
+	if keep {
+		l.hunts = append(l.hunts, h)
+	}
+	return true
+}
+
+// drain returns the recorded hunts, and starts a new summary period.
+func (l *huntLog) drain() []hunt {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	result := l.hunts
+	l.hunts = nil
+	return result
+}
+
+// huntOf returns the hunt of a spot made by a tracked callsign, other than
+// a self-spot, or false.
+func huntOf(spot Spot, tracked []string) (hunt, bool) {
+	hunter := hams.BaseCall(spot.Spotter)
+	if hunter == "" || hunter == hams.BaseCall(spot.Callsign) || !slices.Contains(tracked, hunter) {
+		return hunt{}, false
+	}
+	return hunt{
+		Hunter:    hunter,
+		Activator: spot.Callsign,
+		Program:   spot.Program,
+		Reference: spot.Reference,
+		Name:      spot.Name,
+		Frequency: spot.Hz.String(),
+		Mode:      spot.Mode,
+		Time:      time.Now(),
+	}, true
+}
+
+// recordHunt records the hunt of a spot, if any, and posts it right away
+// when there is no daily summary.
+func recordHunt(discord *discordgo.Session, spot Spot, tracked []string) {
+	if HuntersChannelID == "" {
+		return
+	}
+	h, ok := huntOf(spot, tracked)
+	if !ok || !hunts.record(h, HuntersSummaryTime != "") {
+		return
+	}
+	if HuntersSummaryTime == "" {
+		message := fmt.Sprintf("🏹 %s is chasing %s at %s (%s) on %s %s\n", h.Hunter, h.Activator, h.Reference, h.Name, h.Frequency, h.Mode)
+		if err := (Discord{discord, HuntersChannelID}).Notify(notify.Message{Title: "Hunters", Text: message}); err != nil {
+			fmt.Println("Error sending message:", err)
+		}
+	}
+}
+
+// huntersSummary formats the hunts, grouped by hunter.
+func huntersSummary(list []hunt) string {
+	byHunter := make(map[string][]hunt)
+	for _, h := range list {
+		byHunter[h.Hunter] = append(byHunter[h.Hunter], h)
+	}
+	hunters := make([]string, 0, len(byHunter))
+	for hunter := range byHunter {
+		hunters = append(hunters, hunter)
+	}
+	// Most active hunters first
+	sort.Slice(hunters, func(i, j int) bool {
+		if len(byHunter[hunters[i]]) != len(byHunter[hunters[j]]) {
+			return len(byHunter[hunters[i]]) > len(byHunter[hunters[j]])
+		}
+		return hunters[i] < hunters[j]
+	})
+
+	var sb strings.Builder
+	sb.WriteString("🏹 **Today's hunters**\n")
+	for _, hunter := range hunters {
+		var chased []string
+		for _, h := range byHunter[hunter] {
+			entry := fmt.Sprintf("%s (%s)", h.Reference, h.Activator)
+			if !slices.Contains(chased, entry) {
+				chased = append(chased, entry)
+			}
+		}
+		sb.WriteString(fmt.Sprintf("- **%s** chased %s\n", hunter, strings.Join(chased, ", ")))
+	}
+	return sb.String()
+}
+
+// nextDaily returns the next occurrence of the local time of day ("15:04")
+// after now.
+func nextDaily(now time.Time, clock string) (time.Time, error) {
+	t, err := time.Parse("15:04", clock)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time of day %q: %w", clock, err)
+	}
+	next := time.Date(now.Year(), now.Month(), now.Day(), t.Hour(), t.Minute(), 0, 0, now.Location())
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next, nil
+}
+
+// postHuntersSummary posts the summary of the hunts recorded since the
+// previous summary, if any.
+func postHuntersSummary(discord *discordgo.Session) {
+	list := hunts.drain()
+	if len(list) == 0 {
+		return
+	}
+	if err := (Discord{discord, HuntersChannelID}).Notify(notify.Message{Title: "Hunters summary", Text: huntersSummary(list)}); err != nil {
+		fmt.Println("Error sending message:", err)
+	}
+}
@@ -0,0 +1,78 @@
+package bot
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHuntOf(t *testing.T) {
+	tracked := []string{"KN6YUH", "AK6EU"}
+
+	if _, ok := huntOf(Spot{Callsign: "W1AW", Spotter: "kn6yuh/m", Reference: "US-0001"}, tracked); !ok {
+		t.Error("Spot made by a tracked callsign should be a hunt")
+	}
+	if _, ok := huntOf(Spot{Callsign: "AK6EU", Spotter: "AK6EU", Reference: "US-0001"}, tracked); ok {
+		t.Error("Self-spots aren't hunts")
+	}
+	if _, ok := huntOf(Spot{Callsign: "W1AW", Spotter: "N0CALL", Reference: "US-0001"}, tracked); ok {
+		t.Error("Spots made by untracked callsigns aren't hunts")
+	}
+}
+
+func TestHuntLog(t *testing.T) {
+	ThrottleTime = time.Hour
+	l := &huntLog{seen: make(map[string]time.Time)}
+	now := time.Now()
+	h := hunt{Hunter: "KN6YUH", Activator: "W1AW", Reference: "US-0001", Time: now}
+
+	if !l.record(h, true) {
+		t.Error("First hunt should be recorded")
+	}
+	h.Time = now.Add(time.Minute)
+	if l.record(h, true) {
+		t.Error("Same hunt shouldn't be recorded twice")
+	}
+	h.Time = now.Add(2 * time.Hour)
+	if !l.record(h, true) {
+		t.Error("Same hunt should be recorded again after the throttle time")
+	}
+
+	if got := l.drain(); len(got) != 2 {
+		t.Errorf("Expected 2 hunts, got %d", len(got))
+	}
+	if got := l.drain(); len(got) != 0 {
+		t.Errorf("Drain should reset the hunts, got %d", len(got))
+	}
+}
+
+func TestHuntersSummary(t *testing.T) {
+	summary := huntersSummary([]hunt{
+		{Hunter: "AK6EU", Activator: "W1AW", Reference: "US-0001"},
+		{Hunter: "KN6YUH", Activator: "K6POTA", Reference: "US-0002"},
+		{Hunter: "KN6YUH", Activator: "W6SOTA", Reference: "W6/CT-001"},
+		{Hunter: "KN6YUH", Activator: "W6SOTA", Reference: "W6/CT-001"},
+	})
+	lines := strings.Split(strings.TrimSpace(summary), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected a title and 2 hunters, got %q", summary)
+	}
+	if lines[1] != "- **KN6YUH** chased US-0002 (K6POTA), W6/CT-001 (W6SOTA)" {
+		t.Errorf("Unexpected first hunter: %q", lines[1])
+	}
+}
+
+func TestNextDaily(t *testing.T) {
+	now := time.Date(2025, 6, 17, 20, 0, 0, 0, time.Local)
+	next, err := nextDaily(now, "21:30")
+	if err != nil || !next.Equal(time.Date(2025, 6, 17, 21, 30, 0, 0, time.Local)) {
+		t.Errorf("Expected today at 21:30, got %v %v", next, err)
+	}
+	next, _ = nextDaily(now, "08:00")
+	if !next.Equal(time.Date(2025, 6, 18, 8, 0, 0, 0, time.Local)) {
+		t.Errorf("Expected tomorrow at 08:00, got %v", next)
+	}
+	if _, err := nextDaily(now, "noon"); err == nil {
+		t.Error("Invalid time should fail")
+	}
+}
@@ -72,7 +72,7 @@ func messageHandler(s *discordgo.Session, m *discordgo.MessageCreate) {
 	}
 
 	// Check if the message is in the correct channels
-	if m.ChannelID != PotaChannelID && m.ChannelID != SotaChannelID {
+	if !isSpotChannel(m.ChannelID) {
 		return
 	}
 
@@ -108,17 +108,28 @@ func messageHandler(s *discordgo.Session, m *discordgo.MessageCreate) {
 		return // No callsign found
 	}
 
+	s.ChannelMessageSend(m.ChannelID, RecentSpots(callsign))
+}
+
+// RecentSpots is the answer to a request of the recent spots of a callsign:
+// the cached spots, else the archived ones, else the current POTA and SOTA
+// spots.
+func RecentSpots(callsign string) string {
+	callsign = strings.ToUpper(callsign)
+
 	// Check Cache
 	spots := getCachedSpots(callsign)
 
-	// If cache is empty, fetch fresh data
+	// If cache is empty, look into the history, then fetch fresh data
+	if len(spots) == 0 {
+		spots = archivedSpots(callsign)
+	}
 	if len(spots) == 0 {
 		spots = fetchFreshSpots(callsign)
 	}
 
 	if len(spots) == 0 {
-		s.ChannelMessageSend(m.ChannelID, fmt.Sprintf("No recent spots found for %s.", callsign))
-		return
+		return fmt.Sprintf("No recent spots found for %s.", callsign)
 	}
 
 	// Format output
@@ -127,11 +138,20 @@ func messageHandler(s *discordgo.Session, m *discordgo.MessageCreate) {
 	for _, spot := range spots {
 		sb.WriteString(fmt.Sprintf("- **%s** [%s] %s %s %s\n", spot.Source, spot.Time, spot.Location, spot.Frequency, spot.Mode))
 	}
-
-	s.ChannelMessageSend(m.ChannelID, sb.String())
+	return sb.String()
 }
 
 func parseAndFormatTime(rawTime string) string {
+	t, err := parseSpotTime(rawTime)
+	if err != nil {
+		return rawTime
+	}
+
+	return t.Local().Format("01/02 15:04")
+}
+
+// parseSpotTime parses the time of a POTA or SOTA spot.
+func parseSpotTime(rawTime string) (time.Time, error) {
 	formats := []string{
 		"2006-01-02 15:04:05",
 		"2006-01-02T15:04:05Z",
@@ -153,12 +173,7 @@ func parseAndFormatTime(rawTime string) string {
 			break
 		}
 	}
-
-	if err != nil {
-		return rawTime
-	}
-
-	return t.Local().Format("01/02 15:04")
+	return t, err
 }
 
 func fetchFreshSpots(callsign string) []DisplaySpot {
@@ -0,0 +1,30 @@
+package bot
+
+import (
+	"testing"
+
+	"github.com/PAARA-org/PAARAbot/history"
+	"github.com/PAARA-org/PAARAbot/pota"
+)
+
+func TestRecentSpots(t *testing.T) {
+	History, _ = history.Open("")
+	defer func() { History = nil }()
+
+	// The cached spots come first
+	updateCache("KN6YUH", DisplaySpot{ID: "POTA-1", Source: "POTA", Time: "10/19 16:00", Location: "US-4491", Frequency: "14062", Mode: "CW"})
+	defer func() {
+		cacheMu.Lock()
+		delete(spotCache, "KN6YUH")
+		cacheMu.Unlock()
+	}()
+	if got, want := RecentSpots("kn6yuh"), "Most recent 10 spots for **KN6YUH**:\n- **POTA** [10/19 16:00] US-4491 14062 CW\n"; got != want {
+		t.Errorf("RecentSpots(kn6yuh) = %q, want %q", got, want)
+	}
+
+	// Then the archived ones
+	archive(fromPota(pota.Spot{SpotID: 2, SpotTime: "2026-10-02T16:00:00", Activator: "AK6EU", Reference: "US-4491", Name: "Superior National Forest", Frequency: "7032", Mode: "CW"}))
+	if got := RecentSpots("AK6EU"); got != "Most recent 10 spots for **AK6EU**:\n- **POTA** ["+parseAndFormatTime("2026-10-02T16:00:00")+"] US-4491 (Superior National Forest) 7.032MHz CW\n" {
+		t.Errorf("RecentSpots(AK6EU) = %q", got)
+	}
+}
@@ -0,0 +1,163 @@
+package bot
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"strings"
+
+	"github.com/PAARA-org/PAARAbot/pota"
+	"github.com/PAARA-org/PAARAbot/sota"
+	"github.com/bwmarrin/discordgo"
+)
+
+// gridSquare returns the 6 characters Maidenhead locator of a position,
+// e.g. CM97bd.
+func gridSquare(lat, lon float64) string {
+	lon, lat = lon+180, lat+90
+	if lon < 0 || lon >= 360 || lat < 0 || lat >= 180 {
+		return ""
+	}
+	return string([]byte{
+		'A' + byte(lon/20), 'A' + byte(lat/10),
+		'0' + byte(math.Mod(lon, 20)/2), '0' + byte(math.Mod(lat, 10)),
+		'a' + byte(math.Mod(lon, 2)*12), 'a' + byte(math.Mod(lat, 1)*24),
+	})
+}
+
+// maxFieldLength is Discord's limit of the embed field values.
+const maxFieldLength = 1024
+
+// joinField joins the values of an embed field, listing as many as fit
+// within Discord's limit, followed by "and N more".
+func joinField(values []string) string {
+	joined := strings.Join(values, ", ")
+	if len(joined) <= maxFieldLength {
+		return joined
+	}
+	var sb strings.Builder
+	for i, v := range values {
+		if i > 0 {
+			v = ", " + v
+		}
+		more := fmt.Sprintf(" and %d more", len(values)-i-1)
+		if sb.Len()+len(v)+len(more) > maxFieldLength {
+			sb.WriteString(fmt.Sprintf(" and %d more", len(values)-i))
+			break
+		}
+		sb.WriteString(v)
+	}
+	return sb.String()
+}
+
+// membersField lists the tracked callsigns spotted at the reference.
+func membersField(ref string) *discordgo.MessageEmbedField {
+	value := "None spotted yet"
+	if calls := activatorsAt(ref); len(calls) > 0 {
+		value = joinField(calls)
+	}
+	return &discordgo.MessageEmbedField{Name: "Members spotted there", Value: value}
+}
+
+// summitEmbed describes a summit of the summits list.
+func summitEmbed(s sota.Summit) *discordgo.MessageEmbed {
+	activations := fmt.Sprintf("%d", s.ActivationCount)
+	if s.ActivationCount > 0 {
+		activations += fmt.Sprintf(" (last on %s by %s)", s.ActivationDate, s.ActivationCall)
+	}
+	grid := gridSquare(s.Latitude, s.Longitude)
+	if grid == "" {
+		grid = "unknown"
+	}
+	embed := &discordgo.MessageEmbed{
+		Title:       fmt.Sprintf("%s %s", s.Code, s.Name),
+		URL:         "https://www.sotadata.org.uk/en/summit/" + s.Code,
+		Description: fmt.Sprintf("%s, %s", s.Association, s.Region),
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: "Altitude", Value: fmt.Sprintf("%dm / %dft", s.AltM, s.AltFt), Inline: true},
+			{Name: "Points", Value: fmt.Sprintf("%d (+%d bonus)", s.Points, s.BonusPoints), Inline: true},
+			{Name: "Activations", Value: activations, Inline: true},
+			{Name: "Coordinates", Value: fmt.Sprintf("%.4f, %.4f", s.Latitude, s.Longitude), Inline: true},
+			{Name: "Grid square", Value: grid, Inline: true},
+		},
+	}
+	if r := sota.IsPota(s.Code); r.IsPota {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: "POTA", Value: fmt.Sprintf("%s (%s)", r.ParkId, r.ParkName)})
+	}
+	embed.Fields = append(embed.Fields, membersField(s.Code))
+	return embed
+}
+
+// parkEmbed describes a park of the parks list.
+func parkEmbed(p pota.Park) *discordgo.MessageEmbed {
+	grid := p.Grid
+	if grid == "" {
+		grid = gridSquare(p.Latitude, p.Longitude)
+	}
+	if grid == "" {
+		grid = "unknown"
+	}
+	embed := &discordgo.MessageEmbed{
+		Title:       fmt.Sprintf("%s %s", p.Reference, p.Name),
+		URL:         "https://pota.app/#/park/" + p.Reference,
+		Description: p.LocationDesc,
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: "Coordinates", Value: fmt.Sprintf("%.4f, %.4f", p.Latitude, p.Longitude), Inline: true},
+			{Name: "Grid square", Value: grid, Inline: true},
+		},
+	}
+	if p.Activations > 0 {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: "Activations", Value: fmt.Sprintf("%d", p.Activations), Inline: true})
+	}
+	if !p.Active {
+		embed.Description += " (inactive)"
+	}
+	if summits := sota.SummitsIn(p.Reference); len(summits) > 0 {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: "SOTA summits", Value: joinField(summits)})
+	}
+	embed.Fields = append(embed.Fields, membersField(p.Reference))
+	return embed
+}
+
+func summitHandler(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	code := strings.ToUpper(strings.TrimSpace(optionValue(i, "code").StringValue()))
+	if sota.SummitsCount() == 0 {
+		respond(s, i, "The summits list isn't loaded, please ask the bot admin to set -summitscsv or -summitsURL.")
+		return
+	}
+	summit, ok := sota.LookupSummit(code)
+	if !ok {
+		respond(s, i, fmt.Sprintf("Unknown summit %s, expected a code such as W6/CT-001.", code))
+		return
+	}
+	respondEmbed(s, i, summitEmbed(summit))
+}
+
+func parkHandler(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	ref := strings.ToUpper(strings.TrimSpace(optionValue(i, "reference").StringValue()))
+	if pota.ParksCount() == 0 {
+		respond(s, i, "The parks list isn't loaded, please ask the bot admin to set -parkscsv.")
+		return
+	}
+	park, ok := pota.LookupPark(ref)
+	if !ok {
+		respond(s, i, fmt.Sprintf("Unknown park %s, expected a reference such as US-4491.", ref))
+		return
+	}
+	respondEmbed(s, i, parkEmbed(park))
+}
+
+// respondEmbed replies to the interaction with an embed everyone can see,
+// along with the attached files if any.
+func respondEmbed(s *discordgo.Session, i *discordgo.InteractionCreate, embed *discordgo.MessageEmbed, files ...*discordgo.File) {
+	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds: []*discordgo.MessageEmbed{embed},
+			Files:  files,
+		},
+	})
+	if err != nil {
+		log.Println("Error responding to interaction:", err)
+	}
+}
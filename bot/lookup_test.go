@@ -0,0 +1,53 @@
+package bot
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/PAARA-org/PAARAbot/history"
+	"github.com/PAARA-org/PAARAbot/pota"
+)
+
+func TestGridSquare(t *testing.T) {
+	tests := []struct {
+		lat, lon float64
+		want     string
+	}{
+		{37.1606, -121.8981, "CM97bd"},
+		{47.7, -91.5, "EN47gq"},
+		{-33.8568, 151.2153, "QF56od"},
+		{91, 0, ""},
+	}
+	for _, tt := range tests {
+		if got := gridSquare(tt.lat, tt.lon); got != tt.want {
+			t.Errorf("gridSquare(%v, %v) = %q, want %q", tt.lat, tt.lon, got, tt.want)
+		}
+	}
+}
+
+func TestJoinField(t *testing.T) {
+	if got := joinField([]string{"W6/CT-001", "W6/CT-002"}); got != "W6/CT-001, W6/CT-002" {
+		t.Errorf("joinField() = %q", got)
+	}
+	var summits []string
+	for i := range 200 {
+		summits = append(summits, fmt.Sprintf("K0M/NE-%03d", i))
+	}
+	got := joinField(summits)
+	if len(got) > maxFieldLength || !strings.HasPrefix(got, "K0M/NE-000, K0M/NE-001") || !strings.HasSuffix(got, " and 116 more") {
+		t.Errorf("joinField() = %d characters: %q", len(got), got)
+	}
+}
+
+func TestParkEmbedUnknownGrid(t *testing.T) {
+	History, _ = history.Open("")
+	defer func() { History = nil }()
+
+	embed := parkEmbed(pota.Park{Reference: "US-0000", Latitude: 91})
+	for _, f := range embed.Fields {
+		if f.Value == "" {
+			t.Errorf("Empty field %q", f.Name)
+		}
+	}
+}
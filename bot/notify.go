@@ -0,0 +1,92 @@
+package bot
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/PAARA-org/PAARAbot/hams"
+	"github.com/PAARA-org/PAARAbot/notify"
+	"github.com/bwmarrin/discordgo"
+)
+
+// Notifiers are the chat services other than Discord receiving the posts:
+// spots, contacts, planned activations and digests.
+var Notifiers []notify.Notifier
+
+// Discord is the notifier posting to a Discord channel, given by ID or as a
+// webhook URL, through discordgo. All the posts of the bot go through it, but
+// it isn't one of the Notifiers: each post goes to the channels routed to its
+// program and group, while the Notifiers get it once. It's called from the
+// loop, as the threads, reactions and edits need the posted message.
+type Discord struct {
+	Session   *discordgo.Session
+	ChannelID string
+}
+
+func (d Discord) Name() string { return "Discord" }
+
+func (d Discord) Notify(m notify.Message) error {
+	_, err := d.Send(m, nil)
+	return err
+}
+
+// Send posts the message with the embeds, and returns the Discord message.
+// See send for the forum channels.
+func (d Discord) Send(m notify.Message, embeds []*discordgo.MessageEmbed) (*discordgo.Message, error) {
+	return send(d.Session, d.ChannelID, m.Title, m.Tags, &discordgo.MessageSend{Content: m.Text, Embeds: embeds})
+}
+
+// notifyAll posts the message with the Notifiers.
+func notifyAll(m notify.Message) {
+	for _, n := range Notifiers {
+		if err := n.Notify(m); err != nil {
+			fmt.Printf("Error notifying %s: %v\n", n.Name(), err)
+		}
+	}
+}
+
+// spotMessage is the message about a spot, with the given text.
+func spotMessage(spot Spot, text string) notify.Message {
+	return notify.Message{
+		Program: spot.Program,
+		Title:   spot.activation(),
+		Text:    text,
+		Tags:    spotTags(spot),
+		Spot: &notify.Spot{
+			Callsign:   spot.Callsign,
+			References: spot.References,
+			Name:       spot.Name,
+			Hz:         int64(spot.Hz),
+			Band:       spot.Band,
+			Mode:       spot.Mode,
+			Comments:   spot.Comments,
+			Spotter:    spot.Spotter,
+			Time:       spot.Time,
+		},
+	}
+}
+
+// notifySpot posts the spot with the Notifiers, in the format of the
+// activator's first group, unless denied by the filters.
+func notifySpot(spot Spot) {
+	if len(Notifiers) == 0 {
+		return
+	}
+	spot.Group = hams.DefaultGroup
+	if groups := hams.GroupsOf(spot.Callsign); len(groups) > 0 {
+		spot.Group = groups[0]
+	}
+	if !allowed(spot, spot.Group) {
+		return
+	}
+	message, err := format(spot)
+	if err != nil {
+		fmt.Println("Error formatting message:", err)
+		return
+	}
+	text := message + summitsNote(spot)
+	for _, first := range spot.Firsts {
+		text = strings.TrimRight(text, " \n") + "\n🎉 " + first
+	}
+	notifyAll(spotMessage(spot, text))
+}
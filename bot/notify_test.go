@@ -0,0 +1,57 @@
+package bot
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/PAARA-org/PAARAbot/filter"
+	"github.com/PAARA-org/PAARAbot/notify"
+	"github.com/PAARA-org/PAARAbot/pota"
+)
+
+// recorder is a Notifier keeping the messages.
+type recorder struct {
+	messages []notify.Message
+}
+
+func (r *recorder) Name() string { return "recorder" }
+
+func (r *recorder) Notify(m notify.Message) error {
+	r.messages = append(r.messages, m)
+	return nil
+}
+
+func TestNotifySpot(t *testing.T) {
+	r := &recorder{}
+	Notifiers = []notify.Notifier{r}
+	defer func() { Notifiers = nil }()
+
+	spot := fromPota(pota.Spot{SpotID: 1, Activator: "KN6YUH", Reference: "US-4491", Frequency: "14062", Mode: "CW"})
+	spot.Firsts = []string{"First activation of US-4491 by KN6YUH"}
+	notifySpot(spot)
+	if len(r.messages) != 1 {
+		t.Fatalf("Expected 1 message, got %d", len(r.messages))
+	}
+	m := r.messages[0]
+	if m.Program != "POTA" || m.Spot == nil || m.Spot.Callsign != "KN6YUH" || m.Spot.Hz != 14062000 || m.Spot.Band != "20m" {
+		t.Errorf("Unexpected message: %+v %+v", m, m.Spot)
+	}
+	if !strings.Contains(m.Text, "KN6YUH") || !strings.HasSuffix(m.Text, "\n🎉 First activation of US-4491 by KN6YUH") {
+		t.Errorf("Unexpected text: %q", m.Text)
+	}
+	if len(m.Tags) == 0 || m.Tags[0] != "POTA" {
+		t.Errorf("Unexpected tags: %v", m.Tags)
+	}
+
+	// Denied spots aren't posted either
+	rules, err := filter.Parse(strings.NewReader("deny band=20m\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	Filters = rules
+	defer func() { Filters = nil }()
+	notifySpot(spot)
+	if len(r.messages) != 1 {
+		t.Errorf("Denied spot was posted: %+v", r.messages[1:])
+	}
+}
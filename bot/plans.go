@@ -0,0 +1,327 @@
+package bot
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/PAARA-org/PAARAbot/bandplan"
+	"github.com/PAARA-org/PAARAbot/hams"
+	"github.com/PAARA-org/PAARAbot/ical"
+	"github.com/PAARA-org/PAARAbot/notify"
+	"github.com/PAARA-org/PAARAbot/plans"
+	"github.com/PAARA-org/PAARAbot/pota"
+	"github.com/PAARA-org/PAARAbot/sota"
+	"github.com/bwmarrin/discordgo"
+)
+
+// Plans stores the planned activations of the tracked callsigns.
+var Plans *plans.Store
+
+// PlanCheckInterval is how often the SOTA alerts and the POTA scheduled
+// activations are fetched.
+var PlanCheckInterval = 15 * time.Minute
+
+// CalendarFile is an optional file where the iCalendar feed of the planned
+// activations is written after each check.
+var CalendarFile string
+
+// ReminderLead is how long before the planned time the reminders are posted.
+var ReminderLead = 30 * time.Minute
+
+// alertDuration is the assumed length of an activation, as alerts only have
+// a start time.
+const alertDuration = 2 * time.Hour
+
+// tracks returns the base callsigns of the roster.
+func tracks(calls []string) map[string]bool {
+	set := make(map[string]bool)
+	for _, call := range calls {
+		set[hams.BaseCall(call)] = true
+	}
+	return set
+}
+
+// describeFrequencies formats frequencies in MHz and their modes, e.g.
+// "20m CW, 40m CW". Frequencies outside the bands are written in MHz.
+func describeFrequencies(mhz []float64, modes []string) string {
+	var parts []string
+	for i, f := range mhz {
+		band, inBand := bandplan.Lookup(bandplan.MHz(f), Region)
+		if !inBand {
+			band = fmt.Sprintf("%.3fMHz", f)
+		}
+		if mode := bandplan.NormalizeMode(modes[i]); mode != "" {
+			band += " " + mode
+		}
+		parts = append(parts, band)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// alertPlans returns the plans of the SOTA alerts of the tracked callsigns.
+func alertPlans(alerts []sota.Alert, calls []string) []plans.Plan {
+	tracked := tracks(calls)
+	var result []plans.Plan
+	for _, a := range alerts {
+		if !tracked[hams.BaseCall(a.ActivatingCallsign)] {
+			continue
+		}
+		start, err := a.Time()
+		if err != nil {
+			fmt.Println("Error parsing SOTA alert:", err)
+			continue
+		}
+		code := a.Summit()
+		name := a.SummitDetails
+		if summit, ok := sota.LookupSummit(code); ok {
+			name = summit.Name
+		}
+		result = append(result, plans.Plan{
+			ID:          fmt.Sprintf("SOTA-%d", a.Id),
+			Program:     "SOTA",
+			Callsign:    strings.ToUpper(a.ActivatingCallsign),
+			Reference:   code,
+			Name:        name,
+			Start:       start,
+			End:         start.Add(alertDuration),
+			Frequencies: describeFrequencies(a.Frequencies()),
+			Comments:    strings.TrimSpace(a.Comments),
+		})
+	}
+	return result
+}
+
+// schedulePlans returns the plans of the POTA scheduled activations of the
+// tracked callsigns.
+func schedulePlans(activations []pota.Activation, calls []string) []plans.Plan {
+	tracked := tracks(calls)
+	var result []plans.Plan
+	for _, a := range activations {
+		if !tracked[hams.BaseCall(a.Activator)] {
+			continue
+		}
+		start, end, err := a.Times()
+		if err != nil {
+			fmt.Println("Error parsing POTA scheduled activation:", err)
+			continue
+		}
+		reference := strings.ToUpper(strings.TrimSpace(a.Reference))
+		name := a.Name
+		if park, ok := pota.LookupPark(reference); ok && name == "" {
+			name = park.Name
+		}
+		frequencies := describeFrequencies(a.ParseFrequencies())
+		if frequencies == "" {
+			frequencies = strings.TrimSpace(a.Frequencies)
+		}
+		result = append(result, plans.Plan{
+			ID:          fmt.Sprintf("POTA-%d", a.ScheduledActivitiesID),
+			Program:     "POTA",
+			Callsign:    strings.ToUpper(a.Activator),
+			Reference:   reference,
+			Name:        name,
+			Start:       start,
+			End:         end,
+			Frequencies: frequencies,
+			Comments:    strings.TrimSpace(a.Comments),
+		})
+	}
+	return result
+}
+
+// when formats the planned time relative to now, e.g. "tomorrow 16:00Z".
+func when(t, now time.Time) string {
+	t, now = t.UTC(), now.UTC()
+	day := func(t time.Time) time.Time { return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC) }
+	switch day(t).Sub(day(now)) {
+	case 0:
+		return t.Format("today 15:04Z")
+	case 24 * time.Hour:
+		return t.Format("tomorrow 15:04Z")
+	default:
+		return t.Format("Mon 01/02 15:04Z")
+	}
+}
+
+// planMessage announces a plan, e.g. "KN6YUH plans W6/CT-001 (Mount
+// Umunhum) tomorrow 16:00Z on 20m CW".
+func planMessage(p plans.Plan, now time.Time) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("📅 **%s** plans %s", p.Callsign, p.Reference))
+	if p.Name != "" {
+		sb.WriteString(fmt.Sprintf(" (%s)", p.Name))
+	}
+	sb.WriteString(" " + when(p.Start, now))
+	if p.Frequencies != "" {
+		sb.WriteString(" on " + p.Frequencies)
+	}
+	if p.Comments != "" {
+		sb.WriteString(fmt.Sprintf(" [%s]", p.Comments))
+	}
+	return sb.String()
+}
+
+// planNotice is the message about a plan, with the given text.
+func planNotice(p plans.Plan, text string) notify.Message {
+	return notify.Message{
+		Program: p.Program,
+		Title:   fmt.Sprintf("%s plans %s", p.Callsign, p.Reference),
+		Text:    text,
+		Tags:    []string{p.Program},
+	}
+}
+
+// announce posts the plan to the channels of the activator, and with the
+// Notifiers.
+func announce(discord *discordgo.Session, p plans.Plan, now time.Time) {
+	var messages []plans.Message
+	for _, r := range routesFor(p.Callsign, p.Program) {
+		m, err := Discord{discord, r.Channel}.Send(planNotice(p, planMessage(p, now)), nil)
+		if err != nil {
+			fmt.Println("Error sending message:", err)
+			continue
+		}
+		messages = append(messages, plans.Message{ChannelID: m.ChannelID, MessageID: m.ID})
+	}
+	if err := Plans.SetAnnouncements(p.ID, messages); err != nil {
+		fmt.Println("Error saving plans:", err)
+	}
+	notifyAll(planNotice(p, planMessage(p, now)))
+}
+
+// editAnnouncements replaces the content of the messages announcing a plan.
+func editAnnouncements(discord *discordgo.Session, p plans.Plan, content string) {
+	for _, m := range p.Announcements {
+		var err error
+		if isWebhook(m.ChannelID) {
+			err = editWebhookMessage(discord, m.ChannelID, m.MessageID, content)
+		} else {
+			_, err = discord.ChannelMessageEdit(m.ChannelID, m.MessageID, content)
+		}
+		if err != nil {
+			fmt.Println("Error editing message:", err)
+		}
+	}
+}
+
+// replyAnnouncements replies to the messages announcing a plan, or posts to
+// the channels of the activator when it wasn't announced.
+func replyAnnouncements(discord *discordgo.Session, p plans.Plan, content string) {
+	if len(p.Announcements) == 0 {
+		for _, r := range routesFor(p.Callsign, p.Program) {
+			if err := (Discord{discord, r.Channel}).Notify(planNotice(p, content)); err != nil {
+				fmt.Println("Error sending message:", err)
+			}
+		}
+		return
+	}
+	for _, m := range p.Announcements {
+		var err error
+		if isWebhook(m.ChannelID) {
+			// Webhooks can't reply
+			err = Discord{discord, m.ChannelID}.Notify(planNotice(p, content))
+		} else {
+			ref := &discordgo.MessageReference{MessageID: m.MessageID, ChannelID: m.ChannelID}
+			_, err = discord.ChannelMessageSendReply(m.ChannelID, content, ref)
+		}
+		if err != nil {
+			fmt.Println("Error sending message:", err)
+		}
+	}
+}
+
+// updatePlans posts the new plans of the program, and updates the
+// announcements of the changed and withdrawn ones.
+func updatePlans(discord *discordgo.Session, program string, current []plans.Plan, now time.Time) {
+	changes, err := Plans.Update(program, current, now)
+	if err != nil {
+		fmt.Println("Error saving plans:", err)
+	}
+	for _, p := range changes.Added {
+		if p.End.After(now) {
+			announce(discord, p, now)
+			syncEvent(discord, p, now)
+		}
+	}
+	for _, p := range changes.Changed {
+		syncEvent(discord, p, now)
+		if len(p.Announcements) == 0 {
+			announce(discord, p, now)
+			continue
+		}
+		updated := planMessage(p, now) + " (updated)"
+		editAnnouncements(discord, p, updated)
+		notifyAll(planNotice(p, updated))
+	}
+	for _, p := range changes.Removed {
+		cancelled := "~~" + strings.TrimPrefix(planMessage(p, now), "📅 ") + "~~ (cancelled)"
+		editAnnouncements(discord, p, cancelled)
+		notifyAll(planNotice(p, cancelled))
+		setEventStatus(discord, p, discordgo.GuildScheduledEventStatusCanceled)
+	}
+}
+
+// checkPlans fetches the SOTA alerts and the POTA scheduled activations of
+// the tracked callsigns, and posts the reminders of the plans starting soon.
+func checkPlans(discord *discordgo.Session, now time.Time) {
+	calls := hams.GetCallSigns()
+	alerts, err := sota.ListAlerts()
+	if err != nil {
+		fmt.Println("Error listing SOTA alerts:", err)
+	} else {
+		updatePlans(discord, "SOTA", alertPlans(alerts, calls), now)
+	}
+	activations, err := pota.ListActivations()
+	if err != nil {
+		fmt.Println("Error listing POTA scheduled activations:", err)
+	} else {
+		updatePlans(discord, "POTA", schedulePlans(activations, calls), now)
+	}
+	if CalendarFile != "" {
+		if err := ical.WriteFile(CalendarFile, Plans.Upcoming(now), now); err != nil {
+			fmt.Println("Error writing calendar:", err)
+		}
+	}
+	remindPlans(discord, now)
+}
+
+// remindPlans reminds the channels of the plans starting soon.
+func remindPlans(discord *discordgo.Session, now time.Time) {
+	for _, p := range Plans.Due(now, ReminderLead) {
+		message := fmt.Sprintf("⏰ **%s** is due at %s in %s", p.Callsign, p.Reference, p.Start.Sub(now).Round(time.Minute))
+		if !p.Start.After(now) {
+			message = fmt.Sprintf("⏰ **%s** is due at %s now", p.Callsign, p.Reference)
+		}
+		if p.Frequencies != "" {
+			message += " on " + p.Frequencies
+		}
+		replyAnnouncements(discord, p, message)
+		notifyAll(planNotice(p, message))
+		if err := Plans.MarkReminded(p.ID); err != nil {
+			fmt.Println("Error saving plans:", err)
+		}
+	}
+}
+
+// linkPlan replies to the announcement of the plan of a spotted activation,
+// so the channel can follow from the alert to the live spot, and starts its
+// scheduled event.
+func linkPlan(discord *discordgo.Session, spot Spot) {
+	p, ok := Plans.Match(spot.Program, spot.Callsign, spot.References, time.Now())
+	if !ok {
+		return
+	}
+	if err := Plans.MarkLive(p.ID); err != nil {
+		fmt.Println("Error saving plans:", err)
+	}
+	setEventStatus(discord, p, discordgo.GuildScheduledEventStatusActive)
+	if len(p.Announcements) == 0 {
+		return
+	}
+	message := fmt.Sprintf("🟢 **%s** is on the air at %s as planned, %s", spot.Callsign, spot.AllReferences(), spot.Hz)
+	if spot.Mode != "" {
+		message += " " + spot.Mode
+	}
+	replyAnnouncements(discord, p, message)
+}
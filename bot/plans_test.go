@@ -0,0 +1,66 @@
+package bot
+
+import (
+	"testing"
+	"time"
+
+	"github.com/PAARA-org/PAARAbot/pota"
+	"github.com/PAARA-org/PAARAbot/sota"
+)
+
+func TestAlertPlans(t *testing.T) {
+	alerts := []sota.Alert{
+		{Id: 1, DateActivated: "2026-10-20T16:00:00", SummitCode: "W6/CT-001", SummitDetails: "Mount Umunhum", Frequency: "14.062-cw, 7.032-cw", ActivatingCallsign: "kn6yuh/p"},
+		{Id: 2, DateActivated: "2026-10-20T18:00:00", SummitCode: "W6/CT-002", ActivatingCallsign: "N0CALL"},
+	}
+	list := alertPlans(alerts, []string{"KN6YUH", "AK6EU"})
+	if len(list) != 1 {
+		t.Fatalf("Expected 1 plan, got %+v", list)
+	}
+	p := list[0]
+	if p.ID != "SOTA-1" || p.Callsign != "KN6YUH/P" || p.Reference != "W6/CT-001" || p.End.Sub(p.Start) != alertDuration {
+		t.Errorf("Unexpected plan: %+v", p)
+	}
+
+	now := time.Date(2026, 10, 19, 21, 0, 0, 0, time.UTC)
+	want := "📅 **KN6YUH/P** plans W6/CT-001 (Mount Umunhum) tomorrow 16:00Z on 20m CW, 40m CW"
+	if got := planMessage(p, now); got != want {
+		t.Errorf("planMessage() = %q, want %q", got, want)
+	}
+}
+
+func TestWhen(t *testing.T) {
+	now := time.Date(2026, 10, 19, 21, 0, 0, 0, time.UTC)
+	tests := map[time.Time]string{
+		now.Add(2 * time.Hour): "today 23:00Z",
+		now.Add(-time.Hour):    "today 20:00Z",
+		now.Add(3 * time.Hour): "tomorrow 00:00Z",
+		now.AddDate(0, 0, 3):   "Thu 10/22 21:00Z",
+	}
+	for at, want := range tests {
+		if got := when(at, now); got != want {
+			t.Errorf("when(%v) = %q, want %q", at, got, want)
+		}
+	}
+}
+
+func TestSchedulePlans(t *testing.T) {
+	activations := []pota.Activation{
+		{ScheduledActivitiesID: 7, Activator: "KN6YUH", Reference: "us-1234", Name: "Big Basin", StartDate: "2026-10-20", StartTime: "16:00", EndTime: "18:00", Frequencies: "14062 CW"},
+		{ScheduledActivitiesID: 8, Activator: "N0CALL", Reference: "US-0001", StartDate: "2026-10-20", StartTime: "16:00"},
+	}
+	list := schedulePlans(activations, []string{"KN6YUH"})
+	if len(list) != 1 {
+		t.Fatalf("Expected 1 plan, got %+v", list)
+	}
+	p := list[0]
+	if p.ID != "POTA-7" || p.Reference != "US-1234" || p.End.Sub(p.Start) != 2*time.Hour {
+		t.Errorf("Unexpected plan: %+v", p)
+	}
+
+	now := time.Date(2026, 10, 19, 21, 0, 0, 0, time.UTC)
+	want := "📅 **KN6YUH** plans US-1234 (Big Basin) tomorrow 16:00Z on 20m CW"
+	if got := planMessage(p, now); got != want {
+		t.Errorf("planMessage() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,78 @@
+package bot
+
+import (
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+
+	"github.com/PAARA-org/PAARAbot/roles"
+	"github.com/bwmarrin/discordgo"
+)
+
+// RoleRules maps spot filters to the Discord roles mentioned in the posts
+// of the matching spots. Members join these roles with /subscribe.
+var RoleRules []roles.Rule
+
+// mentions returns the mentions of the roles whose rules match the spots.
+func mentions(spots ...Spot) string {
+	var ids []string
+	for _, spot := range spots {
+		for _, r := range RoleRules {
+			if r.Matches(spot.Program, spot.Band, spot.NormalizedMode, spot.Points) && !slices.Contains(ids, r.RoleID) {
+				ids = append(ids, r.RoleID)
+			}
+		}
+	}
+	var sb strings.Builder
+	for _, id := range ids {
+		sb.WriteString(fmt.Sprintf("<@&%s> ", id))
+	}
+	return sb.String()
+}
+
+func subscribeHandler(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	updateRole(s, i, true)
+}
+
+func unsubscribeHandler(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	updateRole(s, i, false)
+}
+
+// updateRole grants (or removes) one of the roles used for spot mentions.
+func updateRole(s *discordgo.Session, i *discordgo.InteractionCreate, grant bool) {
+	if i.GuildID == "" {
+		respond(s, i, "This command can only be used in a server.")
+		return
+	}
+
+	roleID := optionValue(i, "role").RoleValue(nil, "").ID
+	available := roles.RoleIDs(RoleRules)
+	if !slices.Contains(available, roleID) {
+		var list []string
+		for _, id := range available {
+			list = append(list, fmt.Sprintf("<@&%s>", id))
+		}
+		respond(s, i, fmt.Sprintf("<@&%s> isn't a spot role. Available roles: %s", roleID, strings.Join(list, ", ")))
+		return
+	}
+
+	userID := interactionUser(i).ID
+	var err error
+	if grant {
+		err = s.GuildMemberRoleAdd(i.GuildID, userID, roleID)
+	} else {
+		err = s.GuildMemberRoleRemove(i.GuildID, userID, roleID)
+	}
+	if err != nil {
+		log.Println("Error updating role:", err)
+		respond(s, i, "Sorry, your roles couldn't be updated.")
+		return
+	}
+
+	if grant {
+		respond(s, i, fmt.Sprintf("You'll be mentioned with <@&%s> on matching spots.", roleID))
+	} else {
+		respond(s, i, fmt.Sprintf("You won't be mentioned with <@&%s> anymore.", roleID))
+	}
+}
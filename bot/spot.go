@@ -0,0 +1,413 @@
+package bot
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"text/template"
+
+	"github.com/PAARA-org/PAARAbot/bandplan"
+	"github.com/PAARA-org/PAARAbot/filter"
+	"github.com/PAARA-org/PAARAbot/hams"
+	"github.com/PAARA-org/PAARAbot/notify"
+	"github.com/PAARA-org/PAARAbot/pota"
+	"github.com/PAARA-org/PAARAbot/sota"
+	"github.com/bwmarrin/discordgo"
+)
+
+// GroupChannels routes the posts of a roster group to a Discord channel.
+// Keys are either a group name (e.g. "friends") or a group name and a
+// program (e.g. "friends/SOTA"). Groups without a route use PotaChannelID
+// or SotaChannelID.
+var GroupChannels = make(map[string]string)
+
+// GroupFormats sets the message format of a roster group, using the same
+// keys as GroupChannels. Formats are parsed with ParseFormat.
+var GroupFormats = make(map[string]*template.Template)
+
+// Filters are the allow/deny rules deciding which spots are posted.
+var Filters filter.Rules
+
+// Region is the ITU region used to tell whether spots are within the bands.
+var Region bandplan.Region = 2
+
+// Spot is the program independent view of a POTA or SOTA spot, used as the
+// data of the message format templates.
+type Spot struct {
+	ID        string
+	Program   string
+	Callsign  string
+	Reference string
+	// References lists all the references of a multi-park (n-fer)
+	// activation, starting with Reference.
+	References []string
+	Name       string
+	Location   string
+	Frequency  string
+	Hz         bandplan.Frequency
+	Band       string
+	OutOfBand  bool
+	Mode       string
+	// NormalizedMode is the canonical name of Mode (e.g. SSB for USB), used
+	// by the filters, roles and tags, while the posts show Mode as spotted.
+	NormalizedMode string
+	Family         string
+	Comments       string
+	Spotter        string
+	Source         string
+	Time           string
+	AltFt          int
+	AltM           int
+	Points         int
+	Group          string
+	// Firsts lists why the activation is celebrated, e.g. a member's first
+	// activation of a park.
+	Firsts []string
+}
+
+// defaultFormats are used for groups without a configured format.
+var defaultFormats = map[string]*template.Template{
+	"POTA": template.Must(ParseFormat("{{.Callsign}} at {{.AllReferences}} ({{.Name}} {{.Location}}) on {{.Frequency}}KHz {{.Mode}} [{{.Comments}}] \n")),
+	"SOTA": template.Must(ParseFormat("{{.Callsign}} at {{.Reference}} ({{.Name}} - {{.AltFt}}ft/{{.AltM}}m) on {{.Frequency}}MHz {{.Mode}} [{{.Comments}}] \n")),
+}
+
+// ParseFormat parses a message format, written using the text/template
+// syntax with a Spot as data, e.g. "{{.Callsign}} is on {{.Reference}}".
+func ParseFormat(format string) (*template.Template, error) {
+	return template.New("format").Parse(format)
+}
+
+func fromPota(v pota.Spot) Spot {
+	hz, err := bandplan.ParseKHz(v.Frequency)
+	if err != nil {
+		fmt.Println("Error parsing POTA frequency:", err)
+	}
+	return withBand(Spot{
+		ID:         fmt.Sprintf("POTA-%d", v.SpotID),
+		Program:    "POTA",
+		Callsign:   v.Activator,
+		Reference:  v.Reference,
+		References: v.References(),
+		Name:       v.Name,
+		Location:   v.LocationDesc,
+		Frequency:  v.Frequency,
+		Hz:         hz,
+		Mode:       v.Mode,
+		Comments:   v.Comments,
+		Spotter:    v.Spotter,
+		Source:     v.Source,
+		Time:       v.SpotTime,
+	})
+}
+
+func fromSota(v sota.Spot) Spot {
+	return withBand(Spot{
+		ID:         fmt.Sprintf("SOTA-%d", v.Id),
+		Program:    "SOTA",
+		Callsign:   v.ActivatorCallsign,
+		Reference:  v.SummitCode,
+		References: []string{v.SummitCode},
+		Name:       v.SummitName,
+		Frequency:  fmt.Sprintf("%.3f", v.Frequency),
+		Hz:         bandplan.MHz(float64(v.Frequency)),
+		Mode:       v.Mode,
+		Comments:   v.Comments,
+		Spotter:    v.Callsign,
+		Source:     sotaSource(v),
+		Time:       v.TimeStamp,
+		AltFt:      v.AltFt,
+		AltM:       v.AltM,
+		Points:     v.Points,
+	})
+}
+
+// sotaSource tells apart the SOTA spots made by RBNHole, which relays the
+// Reverse Beacon Network skimmers, from the ones made on SOTAwatch.
+func sotaSource(v sota.Spot) string {
+	if strings.Contains(strings.ToUpper(v.Callsign), "RBNHOLE") {
+		return "RBNHOLE"
+	}
+	return "SOTAWATCH"
+}
+
+// withBand fills the band of the spot from its frequency, and normalizes its
+// mode.
+func withBand(spot Spot) Spot {
+	band, inBand := bandplan.Lookup(spot.Hz, Region)
+	spot.Band, spot.OutOfBand = band, !inBand
+	spot.NormalizedMode = bandplan.NormalizeMode(spot.Mode)
+	spot.Family = bandplan.ModeFamily(spot.NormalizedMode)
+	return spot
+}
+
+// lookup returns the value configured for the group and program, falling
+// back to the value configured for the group alone.
+func lookup[T any](m map[string]T, group, program string) (T, bool) {
+	if v, ok := m[group+"/"+program]; ok {
+		return v, true
+	}
+	v, ok := m[group]
+	return v, ok
+}
+
+// channelFor returns the channel receiving the program's posts for a group.
+func channelFor(group, program string) string {
+	if channel, ok := lookup(GroupChannels, group, program); ok {
+		return channel
+	}
+	if program == "SOTA" {
+		return SotaChannelID
+	}
+	return PotaChannelID
+}
+
+// format renders the spot with the format configured for its group.
+func format(spot Spot) (string, error) {
+	tmpl, ok := lookup(GroupFormats, spot.Group, spot.Program)
+	if !ok {
+		tmpl = defaultFormats[spot.Program]
+	}
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, spot); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+// isSpotChannel reports whether the bot posts spots in the channel.
+func isSpotChannel(channelID string) bool {
+	if channelID == PotaChannelID || channelID == SotaChannelID {
+		return true
+	}
+	for _, channel := range GroupChannels {
+		if channel == channelID {
+			return true
+		}
+	}
+	return false
+}
+
+// route is a channel receiving posts, along with the roster group whose
+// format is used in that channel.
+type route struct {
+	Channel string
+	Group   string
+}
+
+// routesFor returns the channels receiving the program's posts for the
+// callsign. Each channel is listed once, with the first group routed to it.
+func routesFor(callsign, program string) []route {
+	groups := hams.GroupsOf(callsign)
+	if len(groups) == 0 {
+		groups = []string{hams.DefaultGroup}
+	}
+
+	var routes []route
+	for _, group := range groups {
+		channel := channelFor(group, program)
+		if channel == "" || slices.ContainsFunc(routes, func(r route) bool { return r.Channel == channel }) {
+			continue
+		}
+		routes = append(routes, route{Channel: channel, Group: group})
+	}
+	return routes
+}
+
+// allowed reports whether the filters allow posting the spot for the group.
+func allowed(spot Spot, group string) bool {
+	allow, rule := Filters.Evaluate(filter.Spot{
+		Callsign:  spot.Callsign,
+		Group:     group,
+		Program:   spot.Program,
+		Reference: spot.Reference,
+		Band:      spot.Band,
+		Mode:      spot.NormalizedMode,
+		Spotter:   spot.Spotter,
+		Comments:  spot.Comments,
+		Source:    spot.Source,
+	})
+	if !allow {
+		fmt.Printf("Spot %s denied for group %s by rule on line %d: %s\n", spot.ID, group, rule.Line, rule.Text)
+	}
+	return allow
+}
+
+// post sends the spot to each channel the activator's groups route it to,
+// unless denied by the filters, and with the Notifiers. It returns the
+// messages sent to text channels, and the posts created in forum channels.
+func post(discord *discordgo.Session, spot Spot) ([]*discordgo.Message, []spotThread) {
+	var messages []*discordgo.Message
+	var forumPosts []spotThread
+	for _, r := range routesFor(spot.Callsign, spot.Program) {
+		if !allowed(spot, r.Group) {
+			continue
+		}
+		spot.Group = r.Group
+		message, err := format(spot)
+		if err != nil {
+			fmt.Println("Error formatting message:", err)
+			continue
+		}
+		var embeds []*discordgo.MessageEmbed
+		if len(spot.Firsts) > 0 {
+			embeds = []*discordgo.MessageEmbed{celebration(spot)}
+		}
+		forum := forumChannel(discord, r.Channel)
+		m, err := Discord{discord, r.Channel}.Send(spotMessage(spot, mentions(spot)+message+summitsNote(spot)), embeds)
+		if err != nil {
+			fmt.Println("Error sending message:", err)
+			continue
+		}
+		if forum != nil {
+			forumPosts = append(forumPosts, spotThread{ID: m.ChannelID, Forum: forum})
+		} else {
+			messages = append(messages, m)
+		}
+	}
+	notifySpot(spot)
+	return messages, forumPosts
+}
+
+// parkSummits returns the summits located in the parks of a POTA spot.
+func (s Spot) parkSummits() []string {
+	var summits []string
+	if s.Program != "POTA" {
+		return nil
+	}
+	for _, ref := range s.References {
+		for _, code := range sota.SummitsIn(ref) {
+			if !slices.Contains(summits, code) {
+				summits = append(summits, code)
+			}
+		}
+	}
+	return summits
+}
+
+// summitsNote annotates the posts of POTA spots in parks containing summits.
+func summitsNote(spot Spot) string {
+	n := len(spot.parkSummits())
+	switch {
+	case n == 0:
+		return ""
+	case n == 1:
+		return "⛰️ Park contains 1 SOTA summit\n"
+	case len(spot.References) > 1:
+		return fmt.Sprintf("⛰️ Parks contain %d SOTA summits\n", n)
+	default:
+		return fmt.Sprintf("⛰️ Park contains %d SOTA summits\n", n)
+	}
+}
+
+// AllReferences returns the references of the activation joined with "/",
+// e.g. "US-4491/US-4816".
+func (s Spot) AllReferences() string {
+	if len(s.References) == 0 {
+		return s.Reference
+	}
+	return strings.Join(s.References, "/")
+}
+
+// activation describes an activation, e.g. "KN6YUH at US-4491/US-4816".
+func (s Spot) activation() string {
+	return fmt.Sprintf("%s at %s", s.Callsign, s.AllReferences())
+}
+
+// activationKeys identifies the activation at each of its references, to
+// throttle its posts. A multi-park activation is throttled as a whole, no
+// matter which of its references are spotted.
+func (s Spot) activationKeys() []string {
+	refs := s.References
+	if len(refs) == 0 {
+		refs = []string{s.Reference}
+	}
+	keys := make([]string, len(refs))
+	for i, ref := range refs {
+		keys[i] = fmt.Sprintf("%s %s at %s", s.Program, hams.BaseCall(s.Callsign), ref)
+	}
+	return keys
+}
+
+// sameActivation reports whether two spots are for the same activation: the
+// same activator and program, and either a shared reference or, for parks,
+// the same frequency (e.g. one spot per park of a 2-fer).
+func sameActivation(a, b Spot) bool {
+	if a.Program != b.Program || hams.BaseCall(a.Callsign) != hams.BaseCall(b.Callsign) {
+		return false
+	}
+	if a.Program == "POTA" && a.Hz != 0 && a.Hz-b.Hz <= contactTolerance && b.Hz-a.Hz <= contactTolerance {
+		return true
+	}
+	return slices.ContainsFunc(a.References, func(ref string) bool { return slices.Contains(b.References, ref) })
+}
+
+// mergeActivations merges the spots of the same activation, so that
+// multi-park activations are posted once, listing all their references.
+func mergeActivations(spots []Spot) []Spot {
+	var merged []Spot
+	for _, spot := range spots {
+		i := slices.IndexFunc(merged, func(m Spot) bool { return sameActivation(m, spot) })
+		if i < 0 {
+			merged = append(merged, spot)
+			continue
+		}
+		for _, ref := range spot.References {
+			if !slices.Contains(merged[i].References, ref) {
+				merged[i].References = append(merged[i].References, ref)
+			}
+		}
+	}
+	return merged
+}
+
+// postSotaInPota posts the SOTA spot in the POTA channels too, if the summit
+// is in a POTA park.
+func postSotaInPota(discord *discordgo.Session, spot Spot) {
+	r := sota.IsPota(spot.Reference)
+	if !r.IsPota {
+		return
+	}
+	message := fmt.Sprintf("%s at %s (%s) on %sMHz %s [from SOTA spot] \n", spot.Callsign, strings.Join(r.ParkIds, "/"), r.ParkName, spot.Frequency, spot.Mode)
+	for _, route := range routesFor(spot.Callsign, "POTA") {
+		if !allowed(spot, route.Group) {
+			continue
+		}
+		m := notify.Message{
+			Program: "POTA",
+			Title:   fmt.Sprintf("%s at %s", spot.Callsign, strings.Join(r.ParkIds, "/")),
+			Text:    message,
+			Tags:    []string{"POTA", spot.Band, spot.NormalizedMode},
+		}
+		if err := (Discord{discord, route.Channel}).Notify(m); err != nil {
+			fmt.Println("Error sending message:", err)
+		}
+	}
+}
+
+// postPotaInSota posts the POTA spot in the SOTA channels too, if its
+// comments mention a summit, e.g. "also W6/CT-001".
+func postPotaInSota(discord *discordgo.Session, spot Spot) {
+	codes := sota.ParseSummitCodes(spot.Comments)
+	if len(codes) == 0 {
+		return
+	}
+	summit := strings.Join(codes, "/")
+	if s, ok := sota.LookupSummit(codes[0]); ok {
+		summit = fmt.Sprintf("%s (%s - %dft)", summit, s.Name, s.AltFt)
+	}
+	message := fmt.Sprintf("%s at %s on %sKHz %s [from POTA spot at %s] \n", spot.Callsign, summit, spot.Frequency, spot.Mode, spot.AllReferences())
+	for _, route := range routesFor(spot.Callsign, "SOTA") {
+		if !allowed(spot, route.Group) {
+			continue
+		}
+		m := notify.Message{
+			Program: "SOTA",
+			Title:   fmt.Sprintf("%s at %s", spot.Callsign, strings.Join(codes, "/")),
+			Text:    message,
+			Tags:    []string{"SOTA", spot.Band, spot.NormalizedMode},
+		}
+		if err := (Discord{discord, route.Channel}).Notify(m); err != nil {
+			fmt.Println("Error sending message:", err)
+		}
+	}
+}
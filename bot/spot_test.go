@@ -0,0 +1,96 @@
+package bot
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PAARA-org/PAARAbot/pota"
+	"github.com/PAARA-org/PAARAbot/sota"
+)
+
+func TestMergeActivations(t *testing.T) {
+	spots := []Spot{
+		fromPota(pota.Spot{SpotID: 1, Activator: "KN6YUH", Reference: "US-4491", Frequency: "14062", Mode: "CW", Comments: "2fer w/ US-4816"}),
+		fromPota(pota.Spot{SpotID: 2, Activator: "KN6YUH/P", Reference: "US-4816", Frequency: "14062.5", Mode: "CW"}),
+		fromPota(pota.Spot{SpotID: 3, Activator: "KN6YUH", Reference: "US-0001", Frequency: "7032", Mode: "CW"}),
+		fromSota(sota.Spot{Id: 4, ActivatorCallsign: "KN6YUH", SummitCode: "W6/CT-001", Frequency: 14.062, Mode: "CW"}),
+		fromSota(sota.Spot{Id: 5, ActivatorCallsign: "KN6YUH", SummitCode: "W6/CT-002", Frequency: 14.062, Mode: "CW"}),
+	}
+
+	merged := mergeActivations(spots)
+	if len(merged) != 4 {
+		t.Fatalf("Expected 4 activations, got %d: %+v", len(merged), merged)
+	}
+	if got := merged[0].AllReferences(); merged[0].ID != "POTA-1" || got != "US-4491/US-4816" {
+		t.Errorf("Unexpected 2-fer: %s %s", merged[0].ID, got)
+	}
+	if got := merged[1].AllReferences(); got != "US-0001" {
+		t.Errorf("Unexpected activation: %s", got)
+	}
+	// Summits are never merged on frequency
+	if merged[2].AllReferences() != "W6/CT-001" || merged[3].AllReferences() != "W6/CT-002" {
+		t.Errorf("Unexpected summits: %+v", merged[2:])
+	}
+}
+
+func TestActivationKeys(t *testing.T) {
+	rl := NewRateLimiter()
+	ThrottleTime = time.Hour
+	twoFer := Spot{Program: "POTA", Callsign: "KN6YUH", Reference: "US-4491", References: []string{"US-4491", "US-4816"}}
+	if !rl.AllowAny(twoFer.activationKeys()) {
+		t.Error("First spot should be allowed")
+	}
+	// A spot of the second park only is the same activation
+	second := Spot{Program: "POTA", Callsign: "KN6YUH/P", Reference: "US-4816", References: []string{"US-4816"}}
+	if rl.AllowAny(second.activationKeys()) {
+		t.Error("Spot of the same activation should be throttled")
+	}
+	// A third park showing up is posted
+	threeFer := Spot{Program: "POTA", Callsign: "KN6YUH", Reference: "US-4491", References: []string{"US-4491", "US-4816", "US-0001"}}
+	if !rl.AllowAny(threeFer.activationKeys()) {
+		t.Error("New reference should be allowed")
+	}
+}
+
+func TestSummitsNote(t *testing.T) {
+	mappings, err := sota.ParsePotaMappings(strings.NewReader(`SummitCode,AssociationName,RegionName,SummitName,AltM,AltFt,GridRef1,GridRef2,Longitude,Latitude,Points,BonusPoints,ValidFrom,ValidTo,ActivationCount,ActivationDate,ActivationCall,ParkName,Pota
+K0M/NE-002,USA - Minnesota ,Northeast,2266,691,2266,-90.3710,47.9297,-90.371,47.9297,8,3,01/10/2013,31/12/2099,0,,,Superior National Forest/Pat Bayle State Forest,US-4491/US-4816
+K0M/NE-003,USA - Minnesota ,Northeast,Misquah Hills,689,2260,-90.5198,47.9749,-90.5198,47.9749,8,3,01/10/2013,31/12/2099,0,,,Superior National Forest,US-4491
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sota.SetMappings(mappings)
+	defer sota.SetMappings(make(map[string]sota.PotaMapping))
+
+	tests := []struct {
+		spot Spot
+		want string
+	}{
+		{fromPota(pota.Spot{Activator: "KN6YUH", Reference: "US-4491"}), "⛰️ Park contains 2 SOTA summits\n"},
+		{fromPota(pota.Spot{Activator: "KN6YUH", Reference: "US-4816"}), "⛰️ Park contains 1 SOTA summit\n"},
+		{fromPota(pota.Spot{Activator: "KN6YUH", Reference: "US-4816", Comments: "2fer US-4491"}), "⛰️ Parks contain 2 SOTA summits\n"},
+		{fromPota(pota.Spot{Activator: "KN6YUH", Reference: "US-0001"}), ""},
+		{fromSota(sota.Spot{ActivatorCallsign: "KN6YUH", SummitCode: "K0M/NE-002"}), ""},
+	}
+	for _, tt := range tests {
+		if got := summitsNote(tt.spot); got != tt.want {
+			t.Errorf("summitsNote(%v) = %q, want %q", tt.spot.References, got, tt.want)
+		}
+	}
+}
+
+func TestSpotMode(t *testing.T) {
+	spot := fromPota(pota.Spot{Activator: "KN6YUH", Reference: "US-4491", Frequency: "14285", Mode: "USB"})
+	if spot.Mode != "USB" || spot.NormalizedMode != "SSB" {
+		t.Errorf("Unexpected modes: %q %q", spot.Mode, spot.NormalizedMode)
+	}
+	// The posts show the mode as spotted
+	if message, err := format(spot); err != nil || !strings.Contains(message, "14285KHz USB") {
+		t.Errorf("format() = %q, %v", message, err)
+	}
+	if tags := spotTags(spot); tags[2] != "SSB" {
+		t.Errorf("spotTags() = %v", tags)
+	}
+}
@@ -0,0 +1,190 @@
+package bot
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/PAARA-org/PAARAbot/hams"
+	"github.com/PAARA-org/PAARAbot/history"
+	"github.com/PAARA-org/PAARAbot/stats"
+	"github.com/bwmarrin/discordgo"
+)
+
+// leaderboardSize is how many members the leaderboard lists. The attached
+// CSV lists all of them.
+const leaderboardSize = 10
+
+var periodOption = &discordgo.ApplicationCommandOption{
+	Type:        discordgo.ApplicationCommandOptionString,
+	Name:        "period",
+	Description: "Period of the statistics, this month by default",
+	Choices: []*discordgo.ApplicationCommandOptionChoice{
+		{Name: "This month", Value: "month"},
+		{Name: "This year", Value: "year"},
+		{Name: "All time", Value: "all"},
+	},
+}
+
+// statsPeriod returns the name and start of the period selected by the
+// command.
+func statsPeriod(i *discordgo.InteractionCreate) (string, time.Time, error) {
+	name := "month"
+	if o := optionValue(i, "period"); o != nil {
+		name = o.StringValue()
+	}
+	from, err := stats.Period(name, time.Now())
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	label := map[string]string{"month": "this month", "year": "this year", "all": "all time"}[name]
+	return label, from, nil
+}
+
+// csvFile attaches the CSV written by write to a response.
+func csvFile(name string, write func(*bytes.Buffer) error) []*discordgo.File {
+	var buf bytes.Buffer
+	if err := write(&buf); err != nil {
+		fmt.Println("Error writing CSV:", err)
+		return nil
+	}
+	return []*discordgo.File{{Name: name, ContentType: "text/csv", Reader: &buf}}
+}
+
+// topList formats the first n keys of the counts, e.g. "20m (5), 40m (2)".
+func topList(counts map[string]int, n int) string {
+	var parts []string
+	for _, k := range stats.Top(counts) {
+		if len(parts) == n {
+			break
+		}
+		parts = append(parts, fmt.Sprintf("%s (%d)", k, counts[k]))
+	}
+	if len(parts) == 0 {
+		return "-"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// memberEmbed describes the statistics of a member.
+func memberEmbed(m stats.Member, period string) *discordgo.MessageEmbed {
+	longest := "-"
+	if m.Longest.Duration() > 0 {
+		longest = fmt.Sprintf("%s on %s, %s", strings.Join(m.Longest.References, "/"),
+			m.Longest.Start.UTC().Format("01/02"), m.Longest.Duration().Round(time.Minute))
+	}
+	return &discordgo.MessageEmbed{
+		Title: fmt.Sprintf("📊 %s, %s", m.Callsign, period),
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: "Activations", Value: fmt.Sprint(m.Activations), Inline: true},
+			{Name: "Parks", Value: fmt.Sprint(m.Parks), Inline: true},
+			{Name: "Summits", Value: fmt.Sprint(m.Summits), Inline: true},
+			{Name: "SOTA points", Value: fmt.Sprint(m.Points), Inline: true},
+			{Name: "Bands", Value: topList(m.Bands, 5), Inline: true},
+			{Name: "Modes", Value: topList(m.Modes, 5), Inline: true},
+			{Name: "Longest activation", Value: longest},
+		},
+	}
+}
+
+// clubEmbed describes the statistics of all the members.
+func clubEmbed(activations []stats.Activation, members []stats.Member, period string) *discordgo.MessageEmbed {
+	parks, summits := make(map[string]bool), make(map[string]bool)
+	bands, modes := make(map[string]int), make(map[string]int)
+	for _, a := range activations {
+		for _, ref := range a.References {
+			if a.Program == "SOTA" {
+				summits[ref] = true
+			} else {
+				parks[ref] = true
+			}
+		}
+	}
+	for _, m := range members {
+		for band, n := range m.Bands {
+			bands[band] += n
+		}
+		for mode, n := range m.Modes {
+			modes[mode] += n
+		}
+	}
+	return &discordgo.MessageEmbed{
+		Title: fmt.Sprintf("📊 Club statistics, %s", period),
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: "Activations", Value: fmt.Sprint(len(activations)), Inline: true},
+			{Name: "Active members", Value: fmt.Sprint(len(members)), Inline: true},
+			{Name: "Parks", Value: fmt.Sprint(len(parks)), Inline: true},
+			{Name: "Summits", Value: fmt.Sprint(len(summits)), Inline: true},
+			{Name: "Bands", Value: topList(bands, 5), Inline: true},
+			{Name: "Modes", Value: topList(modes, 5), Inline: true},
+		},
+	}
+}
+
+// leaderboard ranks the members.
+func leaderboard(members []stats.Member) string {
+	if len(members) == 0 {
+		return "No activations yet."
+	}
+	var sb strings.Builder
+	for rank, m := range members {
+		if rank == leaderboardSize {
+			break
+		}
+		sb.WriteString(fmt.Sprintf("%d. **%s**: %d activations, %d parks, %d summits, %d points\n",
+			rank+1, m.Callsign, m.Activations, m.Parks, m.Summits, m.Points))
+	}
+	return sb.String()
+}
+
+// statsHandler replies with the statistics of the club, or of a callsign.
+// They are computed from the archive, so the response is deferred.
+func statsHandler(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	period, from, err := statsPeriod(i)
+	if err != nil {
+		respond(s, i, err.Error())
+		return
+	}
+	deferResponse(s, i)
+
+	o := optionValue(i, "callsign")
+	if o == nil {
+		activations := stats.Activations(History.Find(history.Query{From: from}))
+		members := stats.Summarize(activations)
+		editResponse(s, i, "", clubEmbed(activations, members, period), csvFile("stats.csv", func(b *bytes.Buffer) error {
+			return stats.WriteMembersCSV(b, members)
+		})...)
+		return
+	}
+
+	call := hams.BaseCall(o.StringValue())
+	own := stats.Activations(History.Find(history.Query{BaseCall: call, From: from}))
+	if len(own) == 0 {
+		editResponse(s, i, fmt.Sprintf("No activations of %s %s.", call, period), nil)
+		return
+	}
+	editResponse(s, i, "", memberEmbed(stats.Summarize(own)[0], period), csvFile(call+".csv", func(b *bytes.Buffer) error {
+		return stats.WriteActivationsCSV(b, own)
+	})...)
+}
+
+// leaderboardHandler ranks the members, deferring its response as
+// statsHandler.
+func leaderboardHandler(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	period, from, err := statsPeriod(i)
+	if err != nil {
+		respond(s, i, err.Error())
+		return
+	}
+	deferResponse(s, i)
+
+	members := stats.Summarize(stats.Activations(History.Find(history.Query{From: from})))
+	embed := &discordgo.MessageEmbed{
+		Title:       fmt.Sprintf("🏆 Leaderboard, %s", period),
+		Description: leaderboard(members),
+	}
+	editResponse(s, i, "", embed, csvFile("leaderboard.csv", func(b *bytes.Buffer) error {
+		return stats.WriteMembersCSV(b, members)
+	})...)
+}
@@ -0,0 +1,35 @@
+package bot
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/PAARA-org/PAARAbot/stats"
+)
+
+func TestLeaderboard(t *testing.T) {
+	var members []stats.Member
+	for _, call := range []string{"KN6YUH", "AK6EU", "W6A", "W6B", "W6C", "W6D", "W6E", "W6F", "W6G", "W6H", "W6I"} {
+		members = append(members, stats.Member{Callsign: call, Activations: 3, Parks: 2, Summits: 1, Points: 4})
+	}
+	board := leaderboard(members)
+	lines := strings.Split(strings.TrimSpace(board), "\n")
+	if len(lines) != leaderboardSize {
+		t.Errorf("Expected %d lines, got %d", leaderboardSize, len(lines))
+	}
+	if lines[0] != "1. **KN6YUH**: 3 activations, 2 parks, 1 summits, 4 points" {
+		t.Errorf("Unexpected first line: %s", lines[0])
+	}
+	if leaderboard(nil) != "No activations yet." {
+		t.Error("Unexpected empty leaderboard")
+	}
+}
+
+func TestTopList(t *testing.T) {
+	if got := topList(map[string]int{"20m": 5, "40m": 2, "2m": 5}, 2); got != "20m (5), 2m (5)" {
+		t.Errorf("topList() = %q", got)
+	}
+	if got := topList(nil, 2); got != "-" {
+		t.Errorf("topList(nil) = %q", got)
+	}
+}
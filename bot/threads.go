@@ -0,0 +1,184 @@
+package bot
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Threads starts a discussion thread on each activation post. The QSYs of
+// the activation are posted in the thread instead of the channel, and the
+// thread is archived after QRT.
+var Threads bool
+
+// QRTTimeout is how long an activation isn't spotted before it's considered
+// over.
+var QRTTimeout = time.Hour
+
+// threadArchiveMinutes is how long Discord waits before archiving an
+// inactive thread, in case the bot doesn't archive it itself.
+const threadArchiveMinutes = 1440
+
+// maxThreadName is the maximum length of a thread name.
+const maxThreadName = 100
+
+// qrtPattern matches the spot comments announcing the end of an activation.
+var qrtPattern = regexp.MustCompile(`(?i)\bQRT\b`)
+
+// spotThread is a thread of an activation: a thread started on its post, or
+// its post in a forum channel.
+type spotThread struct {
+	ID string
+	// Forum is the forum channel of a forum post, whose tags follow the
+	// band and mode of the activation.
+	Forum *discordgo.Channel
+}
+
+// activationThread follows an activation posted with a thread.
+type activationThread struct {
+	keys    []string
+	threads []spotThread
+	// spot is the last spot of the activation, to tell QSYs.
+	spot Spot
+	seen time.Time
+}
+
+// activeThreads are the threads of the ongoing activations. They're only
+// used by the Run loop.
+var activeThreads []*activationThread
+
+// spottedAt returns the time of the spot, or now if it can't be parsed.
+func spottedAt(spot Spot, now time.Time) time.Time {
+	if t, err := parseSpotTime(spot.Time); err == nil && t.Before(now) {
+		return t
+	}
+	return now
+}
+
+// findThread returns the thread of the spot's activation, if any.
+func findThread(spot Spot) *activationThread {
+	keys := spot.activationKeys()
+	for _, t := range activeThreads {
+		if slices.ContainsFunc(keys, func(key string) bool { return slices.Contains(t.keys, key) }) {
+			return t
+		}
+	}
+	return nil
+}
+
+// startThreads starts a thread on each message of the spot, if Threads is
+// set, and follows them along with the forum posts of the spot.
+func startThreads(discord *discordgo.Session, spot Spot, messages []*discordgo.Message, forumPosts []spotThread, now time.Time) {
+	t := &activationThread{keys: spot.activationKeys(), threads: forumPosts, spot: spot, seen: spottedAt(spot, now)}
+	for _, m := range messages {
+		if !Threads {
+			break
+		}
+		thread, err := discord.MessageThreadStartComplex(m.ChannelID, m.ID, &discordgo.ThreadStart{
+			Name:                truncate(spot.activation(), maxThreadName),
+			AutoArchiveDuration: threadArchiveMinutes,
+		})
+		if err != nil {
+			fmt.Println("Error starting thread:", err)
+			continue
+		}
+		t.threads = append(t.threads, spotThread{ID: thread.ID})
+	}
+	if len(t.threads) > 0 {
+		activeThreads = append(activeThreads, t)
+	}
+}
+
+// qsy describes the change of frequency or mode between two spots, if any.
+func qsy(from, to Spot) string {
+	moved := to.Hz != 0 && (to.Hz-from.Hz > contactTolerance || from.Hz-to.Hz > contactTolerance)
+	if !moved && to.NormalizedMode == from.NormalizedMode {
+		return ""
+	}
+	message := fmt.Sprintf("🔀 QSY to %s", to.Hz)
+	if to.Band != "" {
+		message += " (" + to.Band + ")"
+	}
+	if to.Mode != "" {
+		message += " " + to.Mode
+	}
+	return message
+}
+
+// sendThreads posts a message in the threads of an activation.
+func sendThreads(discord *discordgo.Session, t *activationThread, message string) {
+	for _, thread := range t.threads {
+		if _, err := discord.ChannelMessageSend(thread.ID, message); err != nil {
+			fmt.Println("Error sending message:", err)
+		}
+	}
+}
+
+// retag updates the tags of the forum posts of an activation to the band
+// and mode of the spot.
+func retag(discord *discordgo.Session, t *activationThread, spot Spot) {
+	for _, thread := range t.threads {
+		if thread.Forum == nil {
+			continue
+		}
+		tags := forumTags(thread.Forum, spotTags(spot))
+		if _, err := discord.ChannelEditComplex(thread.ID, &discordgo.ChannelEdit{AppliedTags: &tags}); err != nil {
+			fmt.Println("Error editing forum post:", err)
+		}
+	}
+}
+
+// followThread posts the QSYs of an activation in its thread, and archives
+// it when the activator goes QRT. It reports whether the activation has a
+// thread, in which case the spot isn't posted to the channel.
+func followThread(discord *discordgo.Session, spot Spot, now time.Time) bool {
+	t := findThread(spot)
+	if t == nil {
+		return false
+	}
+	if seen := spottedAt(spot, now); seen.After(t.seen) {
+		t.seen = seen
+	}
+	// New parks of a multi-park activation
+	for _, key := range spot.activationKeys() {
+		if !slices.Contains(t.keys, key) {
+			t.keys = append(t.keys, key)
+		}
+	}
+	if qrtPattern.MatchString(spot.Comments) {
+		closeThread(discord, t, fmt.Sprintf("🔴 %s is QRT [%s]", spot.Callsign, spot.Comments))
+		return true
+	}
+	if message := qsy(t.spot, spot); message != "" {
+		sendThreads(discord, t, message)
+		retag(discord, t, spot)
+	}
+	t.spot = spot
+	return true
+}
+
+// closeThread posts the final message of an activation, and archives its
+// threads.
+func closeThread(discord *discordgo.Session, t *activationThread, message string) {
+	sendThreads(discord, t, message)
+	archived := true
+	for _, thread := range t.threads {
+		if _, err := discord.ChannelEditComplex(thread.ID, &discordgo.ChannelEdit{Archived: &archived}); err != nil {
+			fmt.Println("Error archiving thread:", err)
+		}
+	}
+	activeThreads = slices.DeleteFunc(activeThreads, func(other *activationThread) bool { return other == t })
+}
+
+// closeStaleThreads archives the threads of the activations whose last spot
+// is older than QRTTimeout.
+func closeStaleThreads(discord *discordgo.Session, now time.Time) {
+	for _, t := range slices.Clone(activeThreads) {
+		if now.Sub(t.seen) >= QRTTimeout {
+			closeThread(discord, t, fmt.Sprintf("🔴 %s is QRT, not spotted for %s", t.spot.Callsign, QRTTimeout))
+		}
+	}
+}
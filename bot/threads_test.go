@@ -0,0 +1,50 @@
+package bot
+
+import (
+	"testing"
+	"time"
+
+	"github.com/PAARA-org/PAARAbot/pota"
+)
+
+func TestQSY(t *testing.T) {
+	spot := fromPota(pota.Spot{Activator: "KN6YUH", Reference: "US-4491", Frequency: "14062", Mode: "CW"})
+	tests := []struct {
+		frequency, mode string
+		want            string
+	}{
+		{"14062.5", "CW", ""},
+		{"7032", "CW", "🔀 QSY to 7.032MHz (40m) CW"},
+		{"14062", "SSB", "🔀 QSY to 14.062MHz (20m) SSB"},
+		// The mode is shown as spotted, but USB is still CW to SSB
+		{"14062", "cw", ""},
+		{"14062", "USB", "🔀 QSY to 14.062MHz (20m) USB"},
+	}
+	for _, tt := range tests {
+		to := fromPota(pota.Spot{Activator: "KN6YUH", Reference: "US-4491", Frequency: tt.frequency, Mode: tt.mode})
+		if got := qsy(spot, to); got != tt.want {
+			t.Errorf("qsy(%s %s) = %q, want %q", tt.frequency, tt.mode, got, tt.want)
+		}
+	}
+}
+
+func TestFindThread(t *testing.T) {
+	defer func() { activeThreads = nil }()
+	now := time.Date(2026, 10, 19, 16, 0, 0, 0, time.UTC)
+	twoFer := fromPota(pota.Spot{Activator: "KN6YUH", Reference: "US-4491", Comments: "2fer US-4816", SpotTime: "2026-10-19T15:50:00", Frequency: "14062"})
+	thread := &activationThread{keys: twoFer.activationKeys(), spot: twoFer, seen: spottedAt(twoFer, now)}
+	activeThreads = append(activeThreads, thread)
+
+	if !thread.seen.Equal(now.Add(-10 * time.Minute)) {
+		t.Errorf("seen = %v", thread.seen)
+	}
+	if findThread(fromPota(pota.Spot{Activator: "KN6YUH/P", Reference: "US-4816"})) != thread {
+		t.Error("findThread should find the thread of a multi-park activation from any of its parks")
+	}
+	if findThread(fromPota(pota.Spot{Activator: "AK6EU", Reference: "US-4816"})) != nil {
+		t.Error("findThread found the thread of another activator")
+	}
+	if !qrtPattern.MatchString("QRT, thanks all!") || qrtPattern.MatchString("QRTTY") {
+		t.Error("Unexpected QRT match")
+	}
+}
@@ -0,0 +1,121 @@
+package bot
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/PAARA-org/PAARAbot/watch"
+	"github.com/bwmarrin/discordgo"
+)
+
+// Watchlists stores the personal watchlists managed with /watch and /unwatch.
+var Watchlists *watch.Store
+
+func watchHandler(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	call := strings.ToUpper(optionValue(i, "callsign").StringValue())
+	added, err := Watchlists.Watch(interactionUser(i).ID, call)
+	switch {
+	case err != nil:
+		log.Println("Error saving watchlist:", err)
+		respond(s, i, "Sorry, your watchlist couldn't be saved.")
+	case !added:
+		respond(s, i, fmt.Sprintf("You're already watching %s.", call))
+	default:
+		respond(s, i, fmt.Sprintf("You'll get a direct message when %s is spotted.", call))
+	}
+}
+
+func unwatchHandler(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	call := strings.ToUpper(optionValue(i, "callsign").StringValue())
+	removed, err := Watchlists.Unwatch(interactionUser(i).ID, call)
+	switch {
+	case err != nil:
+		log.Println("Error saving watchlist:", err)
+		respond(s, i, "Sorry, your watchlist couldn't be saved.")
+	case !removed:
+		respond(s, i, fmt.Sprintf("You weren't watching %s.", call))
+	default:
+		respond(s, i, fmt.Sprintf("You won't get direct messages for %s anymore.", call))
+	}
+}
+
+func watchlistHandler(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	userID := interactionUser(i).ID
+	calls := Watchlists.List(userID)
+	if len(calls) == 0 {
+		respond(s, i, "Your watchlist is empty, use /watch to add a callsign.")
+		return
+	}
+
+	settings := Watchlists.Settings(userID)
+	throttle := settings.Throttle
+	if throttle == 0 {
+		throttle = Watchlists.DefaultThrottle
+	}
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("You're watching: %s\n", strings.Join(calls, ", ")))
+	sb.WriteString(fmt.Sprintf("Throttle: %s\n", throttle))
+	if settings.QuietStart != settings.QuietEnd {
+		tz := settings.Timezone
+		if tz == "" {
+			tz = "UTC"
+		}
+		sb.WriteString(fmt.Sprintf("Quiet hours: %02d:00 to %02d:00 %s\n", settings.QuietStart, settings.QuietEnd, tz))
+	}
+	respond(s, i, sb.String())
+}
+
+func watchSettingsHandler(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	userID := interactionUser(i).ID
+	settings := Watchlists.Settings(userID)
+
+	if o := optionValue(i, "throttle"); o != nil {
+		d, err := time.ParseDuration(o.StringValue())
+		if err != nil || d < 0 {
+			respond(s, i, fmt.Sprintf("Invalid throttle %q, use a duration like 30m or 2h.", o.StringValue()))
+			return
+		}
+		settings.Throttle = d
+	}
+	if o := optionValue(i, "quiet_start"); o != nil {
+		settings.QuietStart = int(o.IntValue())
+	}
+	if o := optionValue(i, "quiet_end"); o != nil {
+		settings.QuietEnd = int(o.IntValue())
+	}
+	if o := optionValue(i, "timezone"); o != nil {
+		settings.Timezone = o.StringValue()
+	}
+
+	if err := Watchlists.SetSettings(userID, settings); err != nil {
+		respond(s, i, fmt.Sprintf("Your settings couldn't be saved: %v", err))
+		return
+	}
+	respond(s, i, "Your watchlist settings were saved.")
+}
+
+// notifyWatchers sends a direct message to the users watching the spot's
+// activator, unless throttled or in their quiet hours.
+func notifyWatchers(s *discordgo.Session, spot Spot) {
+	activation := fmt.Sprintf("%s at %s", spot.Callsign, spot.Reference)
+	for _, userID := range Watchlists.Watchers(spot.Callsign) {
+		if !Watchlists.Allow(userID, activation, time.Now()) {
+			continue
+		}
+		message, err := format(spot)
+		if err != nil {
+			log.Println("Error formatting message:", err)
+			return
+		}
+		channel, err := s.UserChannelCreate(userID)
+		if err != nil {
+			log.Println("Error opening direct message channel:", err)
+			continue
+		}
+		if _, err := s.ChannelMessageSend(channel.ID, "👀 "+message); err != nil {
+			log.Println("Error sending direct message:", err)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package bot
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// WebhookUsernames and WebhookAvatars set the name and avatar URL of the
+// webhook posts of each program (POTA or SOTA). Posts without a program,
+// or programs without an entry, use the settings of the webhook.
+var (
+	WebhookUsernames = make(map[string]string)
+	WebhookAvatars   = make(map[string]string)
+)
+
+// webhookPattern matches Discord webhook URLs, capturing the webhook ID and
+// token.
+var webhookPattern = regexp.MustCompile(`^https://(?:[a-z]+\.)?discord(?:app)?\.com/api/(?:v\d+/)?webhooks/(\d+)/([\w-]+)/?$`)
+
+// isWebhook reports whether a channel is given as a webhook URL, instead of
+// a channel ID.
+func isWebhook(channel string) bool {
+	return webhookPattern.MatchString(channel)
+}
+
+// ValidWebhook checks a webhook URL.
+func ValidWebhook(url string) error {
+	if !isWebhook(url) {
+		return fmt.Errorf("invalid Discord webhook URL %q, expected https://discord.com/api/webhooks/<id>/<token>", url)
+	}
+	return nil
+}
+
+// executeWebhook posts a message through a webhook, as the program. The
+// ChannelID of the returned message is the webhook URL, so it can be edited
+// later with editWebhookMessage.
+func executeWebhook(discord *discordgo.Session, url, program string, data *discordgo.MessageSend) (*discordgo.Message, error) {
+	parts := webhookPattern.FindStringSubmatch(url)
+	if parts == nil {
+		return nil, ValidWebhook(url)
+	}
+	m, err := discord.WebhookExecute(parts[1], parts[2], true, &discordgo.WebhookParams{
+		Content:   data.Content,
+		Username:  WebhookUsernames[program],
+		AvatarURL: WebhookAvatars[program],
+		Embeds:    data.Embeds,
+		Files:     data.Files,
+	})
+	if err != nil {
+		return nil, err
+	}
+	m.ChannelID = url
+	return m, nil
+}
+
+// editWebhookMessage replaces the content of a message posted through a
+// webhook.
+func editWebhookMessage(discord *discordgo.Session, url, messageID, content string) error {
+	parts := webhookPattern.FindStringSubmatch(url)
+	if parts == nil {
+		return ValidWebhook(url)
+	}
+	_, err := discord.WebhookMessageEdit(parts[1], parts[2], messageID, &discordgo.WebhookEdit{Content: &content})
+	return err
+}
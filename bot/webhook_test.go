@@ -0,0 +1,66 @@
+package bot
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/PAARA-org/PAARAbot/notify"
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestIsWebhook(t *testing.T) {
+	tests := map[string]bool{
+		"https://discord.com/api/webhooks/123456/abc-DEF_ghi": true,
+		"https://discordapp.com/api/webhooks/123456/abc":      true,
+		"https://ptb.discord.com/api/v10/webhooks/123456/abc": true,
+		"123456789012345678":                                        false,
+		"https://example.com/api/webhooks/123456/abc":               false,
+		"https://discord.com/api/webhooks/123456/abc/../../channel": false,
+	}
+	for url, want := range tests {
+		if got := isWebhook(url); got != want {
+			t.Errorf("isWebhook(%q) = %v, want %v", url, got, want)
+		}
+	}
+}
+
+func TestSendWebhook(t *testing.T) {
+	var got discordgo.WebhookParams
+	var path string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		json.NewDecoder(r.Body).Decode(&got)
+		json.NewEncoder(w).Encode(discordgo.Message{ID: "42", ChannelID: "7"})
+	}))
+	defer server.Close()
+	defer func(endpoint string) { discordgo.EndpointWebhooks = endpoint }(discordgo.EndpointWebhooks)
+	discordgo.EndpointWebhooks = server.URL + "/webhooks/"
+
+	WebhookUsernames["POTA"], WebhookAvatars["POTA"] = "POTA spots", "https://example.com/pota.png"
+	defer func() { delete(WebhookUsernames, "POTA"); delete(WebhookAvatars, "POTA") }()
+
+	discord, _ := discordgo.New("")
+	url := "https://discord.com/api/webhooks/123/secret"
+	m, err := (Discord{discord, url}).Send(notify.Message{Title: "KN6YUH at US-4491", Text: "KN6YUH at US-4491", Tags: []string{"POTA", "20m", "CW"}}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if path != "/webhooks/123/secret" {
+		t.Errorf("Posted to %s", path)
+	}
+	if got.Content != "KN6YUH at US-4491" || got.Username != "POTA spots" || got.AvatarURL != "https://example.com/pota.png" {
+		t.Errorf("Unexpected webhook params: %+v", got)
+	}
+	if m.ID != "42" || m.ChannelID != url {
+		t.Errorf("Unexpected message: %+v", m)
+	}
+
+	// Other programs use the settings of the webhook
+	got = discordgo.WebhookParams{}
+	(Discord{discord, url}).Notify(notify.Message{Text: "KN6YUH at W6/CT-001", Tags: []string{"SOTA"}})
+	if got.Username != "" || got.AvatarURL != "" {
+		t.Errorf("Unexpected webhook params: %+v", got)
+	}
+}
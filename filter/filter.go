@@ -0,0 +1,317 @@
+// This package implements the allow/deny rules deciding which spots are
+// posted. Rules are evaluated in order, and the first rule matching a spot
+// decides whether it's allowed or denied. Spots matching no rule are allowed.
+package filter
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/PAARA-org/PAARAbot/bandplan"
+)
+
+// Spot holds the fields of a spot the rules can test.
+type Spot struct {
+	Callsign  string
+	Group     string
+	Program   string
+	Reference string
+	Band      string
+	Mode      string
+	Spotter   string
+	Comments  string
+	Source    string
+}
+
+// Fields lists the names of the fields usable in conditions.
+var Fields = []string{"callsign", "group", "program", "reference", "band", "mode", "spotter", "comments", "source"}
+
+// operators are listed longest first, so that "!=" isn't parsed as "=".
+var operators = []string{"!=", "^=", "=", "~"}
+
+// Condition tests one field of a spot. The operators are:
+//   - "=" the field equals one of the comma separated values
+//   - "!=" the field equals none of the comma separated values
+//   - "^=" the field starts with one of the comma separated values
+//   - "~" the field matches the regular expression
+//
+// Comparisons are case insensitive, except for regular expressions (use
+// (?i) for that). Modes are normalized, and also match their family (e.g.
+// mode=DATA matches FT8 spots).
+type Condition struct {
+	Field  string
+	Op     string
+	Values []string
+	re     *regexp.Regexp
+}
+
+// Rule is an allow or deny rule, matching spots meeting all its conditions.
+type Rule struct {
+	Allow      bool
+	Conditions []Condition
+	// Line is the rule's line number in the file, and Text its source.
+	Line int
+	Text string
+}
+
+// Rules is an ordered list of rules.
+type Rules []Rule
+
+// ParseFile parses a file with one rule per line, e.g.
+//
+//	# FT8 robot spots of KN6YUH's home station
+//	deny callsign=KN6YUH mode=FT8 source=RBN
+//	deny comments~(?i)\bQRT\b
+//	deny band=2m comments~"(?i)just testing"
+//	allow group=members
+//
+// Empty lines, and lines commented with # or //, are ignored.
+func ParseFile(filePath string) (Rules, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file %s: %w", filePath, err)
+	}
+	defer file.Close()
+	return Parse(file)
+}
+
+// Parse parses the rules from r, formatted as described in ParseFile.
+func Parse(r io.Reader) (Rules, error) {
+	var rules Rules
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		trimmedLine := strings.TrimSpace(scanner.Text())
+		if trimmedLine == "" || strings.HasPrefix(trimmedLine, "#") || strings.HasPrefix(trimmedLine, "//") {
+			continue
+		}
+		rule, err := ParseRule(trimmedLine)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		rule.Line = line
+		rules = append(rules, rule)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading rules: %w", err)
+	}
+	return rules, nil
+}
+
+// ParseRule parses a single rule: "allow" or "deny", followed by one or more
+// space separated conditions. Values containing spaces must be quoted.
+func ParseRule(text string) (Rule, error) {
+	tokens, err := tokenize(text)
+	if err != nil {
+		return Rule{}, err
+	}
+	if len(tokens) < 2 {
+		return Rule{}, fmt.Errorf("expected allow or deny followed by conditions, got %q", text)
+	}
+
+	rule := Rule{Text: text}
+	switch strings.ToLower(tokens[0]) {
+	case "allow":
+		rule.Allow = true
+	case "deny":
+		rule.Allow = false
+	default:
+		return Rule{}, fmt.Errorf("expected allow or deny, got %q", tokens[0])
+	}
+
+	for _, token := range tokens[1:] {
+		c, err := ParseCondition(token)
+		if err != nil {
+			return Rule{}, err
+		}
+		rule.Conditions = append(rule.Conditions, c)
+	}
+	return rule, nil
+}
+
+// ParseCondition parses a "field<op>value" condition.
+func ParseCondition(token string) (Condition, error) {
+	i := strings.IndexAny(token, "!^=~")
+	if i <= 0 {
+		return Condition{}, fmt.Errorf("expected field<op>value condition, got %q", token)
+	}
+	c := Condition{Field: strings.ToLower(token[:i])}
+	if !slices.Contains(Fields, c.Field) {
+		return Condition{}, fmt.Errorf("unknown field %q, expected one of %s", c.Field, strings.Join(Fields, ", "))
+	}
+	for _, op := range operators {
+		if strings.HasPrefix(token[i:], op) {
+			c.Op = op
+			break
+		}
+	}
+	if c.Op == "" {
+		return Condition{}, fmt.Errorf("unknown operator in %q", token)
+	}
+
+	value := token[i+len(c.Op):]
+	if value == "" {
+		return Condition{}, fmt.Errorf("missing value in %q", token)
+	}
+	if c.Op == "~" {
+		re, err := regexp.Compile(value)
+		if err != nil {
+			return Condition{}, fmt.Errorf("invalid regular expression in %q: %w", token, err)
+		}
+		c.re = re
+		c.Values = []string{value}
+		return c, nil
+	}
+	for _, v := range strings.Split(value, ",") {
+		c.Values = append(c.Values, strings.ToUpper(strings.TrimSpace(v)))
+	}
+	return c, nil
+}
+
+// tokenize splits the text on spaces, keeping double quoted strings (with
+// the quotes removed) as a single token.
+func tokenize(text string) ([]string, error) {
+	var tokens []string
+	var current strings.Builder
+	inQuotes, inToken := false, false
+	for _, r := range text {
+		switch {
+		case r == '"':
+			inQuotes = !inQuotes
+			inToken = true
+		case !inQuotes && (r == ' ' || r == '\t'):
+			if inToken {
+				tokens = append(tokens, current.String())
+				current.Reset()
+				inToken = false
+			}
+		default:
+			current.WriteRune(r)
+			inToken = true
+		}
+	}
+	if inQuotes {
+		return nil, fmt.Errorf("unterminated quote in %q", text)
+	}
+	if inToken {
+		tokens = append(tokens, current.String())
+	}
+	return tokens, nil
+}
+
+// field returns the value of the named field.
+func (s Spot) field(name string) string {
+	switch name {
+	case "callsign":
+		return s.Callsign
+	case "group":
+		return s.Group
+	case "program":
+		return s.Program
+	case "reference":
+		return s.Reference
+	case "band":
+		return s.Band
+	case "mode":
+		return s.Mode
+	case "spotter":
+		return s.Spotter
+	case "comments":
+		return s.Comments
+	case "source":
+		return s.Source
+	}
+	return ""
+}
+
+// Matches reports whether the spot meets the condition.
+func (c Condition) Matches(s Spot) bool {
+	value := s.field(c.Field)
+	if c.Op == "~" {
+		return c.re.MatchString(value)
+	}
+
+	value = strings.ToUpper(strings.TrimSpace(value))
+	found := slices.ContainsFunc(c.Values, func(v string) bool {
+		switch {
+		case c.Op == "^=":
+			return strings.HasPrefix(value, v)
+		case c.Field == "mode":
+			return v == bandplan.ModeFamily(value) || bandplan.NormalizeMode(v) == bandplan.NormalizeMode(value)
+		default:
+			return value == v
+		}
+	})
+	if c.Op == "!=" {
+		return !found
+	}
+	return found
+}
+
+// Matches reports whether the spot meets all the rule's conditions.
+func (r Rule) Matches(s Spot) bool {
+	for _, c := range r.Conditions {
+		if !c.Matches(s) {
+			return false
+		}
+	}
+	return true
+}
+
+// Evaluate returns whether the spot is allowed, along with the rule that
+// decided it, or nil if no rule matched.
+func (rs Rules) Evaluate(s Spot) (bool, *Rule) {
+	for i := range rs {
+		if rs[i].Matches(s) {
+			return rs[i].Allow, &rs[i]
+		}
+	}
+	return true, nil
+}
+
+// Allow reports whether the spot is allowed by the rules.
+func (rs Rules) Allow(s Spot) bool {
+	allowed, _ := rs.Evaluate(s)
+	return allowed
+}
+
+// ParseSpot builds a sample spot from "field=value" arguments, e.g. for
+// testing the rules from the command line.
+func ParseSpot(args []string) (Spot, error) {
+	var s Spot
+	for _, arg := range args {
+		name, value, found := strings.Cut(arg, "=")
+		if !found {
+			return Spot{}, fmt.Errorf("expected field=value, got %q", arg)
+		}
+		switch strings.ToLower(name) {
+		case "callsign":
+			s.Callsign = value
+		case "group":
+			s.Group = value
+		case "program":
+			s.Program = value
+		case "reference":
+			s.Reference = value
+		case "band":
+			s.Band = value
+		case "mode":
+			s.Mode = value
+		case "spotter":
+			s.Spotter = value
+		case "comments":
+			s.Comments = value
+		case "source":
+			s.Source = value
+		default:
+			return Spot{}, fmt.Errorf("unknown field %q, expected one of %s", name, strings.Join(Fields, ", "))
+		}
+	}
+	return s, nil
+}
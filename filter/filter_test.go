@@ -0,0 +1,99 @@
+package filter
+
+import (
+	"strings"
+	"testing"
+)
+
+const testRules = `
+# FT8 robot spots of a member's home station
+deny callsign=KN6YUH mode=FT8 source=RBN
+deny comments~(?i)\bQRT\b
+// Tests on 2m
+deny band=2m comments~"(?i)just testing"
+deny reference^=XX-,YY-
+allow group=members
+deny group!=members
+`
+
+func TestEvaluate(t *testing.T) {
+	rules, err := Parse(strings.NewReader(testRules))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(rules) != 6 {
+		t.Fatalf("Expected 6 rules, got %d", len(rules))
+	}
+
+	tests := []struct {
+		name  string
+		spot  Spot
+		allow bool
+		line  int
+	}{
+		{"robot spot", Spot{Callsign: "kn6yuh", Mode: "FT-8", Source: "rbn", Group: "members"}, false, 3},
+		{"manual spot", Spot{Callsign: "KN6YUH", Mode: "FT8", Source: "Web", Group: "members"}, true, 8},
+		{"QRT", Spot{Comments: "going qrt, 73", Group: "members"}, false, 4},
+		{"QRT within a word", Spot{Comments: "QRTX", Group: "members"}, true, 8},
+		{"2m test", Spot{Band: "2m", Comments: "Just testing the HT", Group: "members"}, false, 6},
+		{"2m activation", Spot{Band: "2m", Comments: "on the summit", Group: "members"}, true, 8},
+		{"reference prefix", Spot{Reference: "YY-0042", Group: "members"}, false, 7},
+		{"other group", Spot{Group: "friends"}, false, 9},
+		{"no group", Spot{}, false, 9},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			allow, rule := rules.Evaluate(tt.spot)
+			if allow != tt.allow {
+				t.Errorf("Evaluate = %v, want %v", allow, tt.allow)
+			}
+			if rule == nil || rule.Line != tt.line {
+				t.Errorf("Expected rule on line %d to decide, got %+v", tt.line, rule)
+			}
+		})
+	}
+}
+
+func TestEvaluateNoMatch(t *testing.T) {
+	rules, _ := Parse(strings.NewReader("deny mode=DATA"))
+	if allow, rule := rules.Evaluate(Spot{Mode: "CW"}); !allow || rule != nil {
+		t.Errorf("Spots matching no rule should be allowed, got %v %v", allow, rule)
+	}
+	if rules.Allow(Spot{Mode: "JS8"}) {
+		t.Error("Mode family should match")
+	}
+
+	rules, _ = Parse(strings.NewReader("deny mode=PHONE\ndeny mode=ft-4"))
+	if rules.Allow(Spot{Mode: "fm"}) || rules.Allow(Spot{Mode: "FT4"}) {
+		t.Error("Mode family and spelling variants should match")
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, line := range []string{
+		"deny",
+		"block mode=CW",
+		"deny color=red",
+		"deny mode",
+		"deny mode=",
+		"deny comments~(",
+		`deny comments~"unterminated`,
+	} {
+		if _, err := ParseRule(line); err == nil {
+			t.Errorf("ParseRule(%q) should fail", line)
+		}
+	}
+}
+
+func TestParseSpot(t *testing.T) {
+	s, err := ParseSpot([]string{"callsign=KN6YUH", "comments=QRT now", "mode=FT8"})
+	if err != nil {
+		t.Fatalf("ParseSpot failed: %v", err)
+	}
+	if s.Callsign != "KN6YUH" || s.Comments != "QRT now" || s.Mode != "FT8" {
+		t.Errorf("ParseSpot mismatch: %+v", s)
+	}
+	if _, err := ParseSpot([]string{"color=red"}); err == nil {
+		t.Error("ParseSpot should fail on unknown fields")
+	}
+}
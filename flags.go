@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/PAARA-org/PAARAbot/hams"
+)
+
+// sourceFlag is a repeatable flag adding roster sources, in command line
+// order, to a list shared by the -hamfile and -csvURL flags.
+type sourceFlag struct {
+	sources *[]rosterSource
+	web     bool
+}
+
+// rosterSource is a roster file (or URL, if web is set) for a group.
+type rosterSource struct {
+	hams.Source
+	web bool
+}
+
+func (f sourceFlag) String() string {
+	if f.sources == nil {
+		return ""
+	}
+	var values []string
+	for _, s := range *f.sources {
+		if s.web == f.web {
+			values = append(values, s.Group+"="+s.Location)
+		}
+	}
+	return strings.Join(values, ",")
+}
+
+func (f sourceFlag) Set(value string) error {
+	*f.sources = append(*f.sources, rosterSource{Source: hams.ParseSource(value), web: f.web})
+	return nil
+}
+
+// mapFlag is a repeatable "key=value" flag.
+type mapFlag map[string]string
+
+func (f mapFlag) String() string {
+	var values []string
+	for k, v := range f {
+		values = append(values, k+"="+v)
+	}
+	return strings.Join(values, ",")
+}
+
+func (f mapFlag) Set(value string) error {
+	k, v, found := strings.Cut(value, "=")
+	if !found || k == "" {
+		return fmt.Errorf("expected key=value, got %q", value)
+	}
+	f[k] = v
+	return nil
+}
+
+// listFlag is a repeatable flag, keeping the values in command line order.
+type listFlag []string
+
+func (f *listFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *listFlag) Set(value string) error {
+	*f = append(*f, value)
+	return nil
+}
@@ -7,13 +7,24 @@ import (
 	"net/http"
 	"net/url"
 	"os"
+	"slices"
 	"strings"
 	"sync"
 )
 
+// DefaultGroup is the group of the callsigns loaded without a group name.
+const DefaultGroup = "members"
+
+// Group is a named list of callsigns, e.g. club members or DX of interest.
+type Group struct {
+	Name      string
+	CallSigns []string
+}
+
 // storage for callsigns
 var (
 	callSigns []string
+	groups    []Group
 	mu        sync.RWMutex
 )
 
@@ -26,11 +37,50 @@ func GetCallSigns() []string {
 	return dst
 }
 
-// SetCallSigns updates the global callsigns list in a thread-safe manner
+// SetCallSigns updates the global callsigns list in a thread-safe manner.
+// All the callsigns are assigned to the DefaultGroup.
 func SetCallSigns(cs []string) {
+	SetGroups([]Group{{Name: DefaultGroup, CallSigns: cs}})
+}
+
+// SetGroups replaces the roster with the given groups. The order of the
+// groups matters: the first group a callsign belongs to takes precedence.
+// GetCallSigns returns the union of all the groups.
+func SetGroups(gs []Group) {
+	var all []string
+	for _, g := range gs {
+		all = append(all, g.CallSigns...)
+	}
+
 	mu.Lock()
 	defer mu.Unlock()
-	callSigns = cs
+	groups = gs
+	callSigns = Unique(all)
+}
+
+// GetGroups returns a thread-safe copy of the current groups
+func GetGroups() []Group {
+	mu.RLock()
+	defer mu.RUnlock()
+	dst := make([]Group, len(groups))
+	for i, g := range groups {
+		dst[i] = Group{Name: g.Name, CallSigns: slices.Clone(g.CallSigns)}
+	}
+	return dst
+}
+
+// GroupsOf returns the names of the groups containing the callsign, in
+// order of precedence.
+func GroupsOf(call string) []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	var names []string
+	for _, g := range groups {
+		if slices.ContainsFunc(g.CallSigns, func(c string) bool { return strings.EqualFold(c, call) }) {
+			names = append(names, g.Name)
+		}
+	}
+	return names
 }
 
 // Unique returns a new slice with unique elements from the input slice.
@@ -0,0 +1,183 @@
+package hams
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// callSignPattern matches a callsign with an optional prefix (e.g. 4O/) and
+// an optional portable suffix (e.g. /P or /6).
+var callSignPattern = regexp.MustCompile(`^([A-Z0-9]{1,4}/)?[A-Z0-9]{1,3}[0-9][A-Z0-9]{0,4}[A-Z](/[A-Z0-9]{1,4})?$`)
+
+// maxListed limits how many callsigns are listed per section in a summary,
+// so the message stays within Discord's message size limit.
+const maxListed = 30
+
+// Problem describes a roster entry that was rejected by Validate.
+type Problem struct {
+	Entry  string
+	Reason string
+}
+
+// Changes summarizes the differences between two roster loads, along with
+// the entries that were rejected while validating the new roster.
+type Changes struct {
+	Added    []string
+	Removed  []string
+	Problems []Problem
+}
+
+// Normalize returns the canonical form of a callsign (trimmed and uppercase).
+func Normalize(call string) string {
+	return strings.ToUpper(strings.TrimSpace(call))
+}
+
+// BaseCall strips the prefixes and suffixes of a callsign (e.g. W6/KN6YUH/P
+// or RBN skimmers' KN6YUH-#), keeping the home callsign.
+func BaseCall(call string) string {
+	call, _, _ = strings.Cut(strings.ToUpper(strings.TrimSpace(call)), "-")
+	base := ""
+	for _, part := range strings.Split(call, "/") {
+		if len(part) > len(base) {
+			base = part
+		}
+	}
+	return base
+}
+
+// Validate normalizes the input entries and returns the unique valid
+// callsigns, in their original order. Entries that are not ASCII, don't look
+// like a callsign, or duplicate an earlier entry with a different case or
+// whitespace are reported as problems. Exact duplicates are silently merged,
+// as the same callsign is expected to appear in several sources.
+func Validate(input []string) (valid []string, problems []Problem) {
+	seen := make(map[string]string)
+	for _, entry := range input {
+		if !isASCII(entry) {
+			problems = append(problems, Problem{Entry: entry, Reason: "contains non-ASCII characters"})
+			continue
+		}
+		call := Normalize(entry)
+		if !callSignPattern.MatchString(call) {
+			problems = append(problems, Problem{Entry: entry, Reason: "not a valid callsign"})
+			continue
+		}
+		if first, exists := seen[call]; exists {
+			if first != entry {
+				problems = append(problems, Problem{Entry: entry, Reason: fmt.Sprintf("duplicate of %q", first)})
+			}
+			continue
+		}
+		seen[call] = entry
+		valid = append(valid, call)
+	}
+	return valid, problems
+}
+
+// Diff returns the callsigns found in current but not in previous (added),
+// and the ones found in previous but not in current (removed).
+func Diff(previous, current []string) (added, removed []string) {
+	before := make(map[string]bool, len(previous))
+	for _, call := range previous {
+		before[call] = true
+	}
+	after := make(map[string]bool, len(current))
+	for _, call := range current {
+		after[call] = true
+		if !before[call] {
+			added = append(added, call)
+		}
+	}
+	for _, call := range previous {
+		if !after[call] {
+			removed = append(removed, call)
+		}
+	}
+	return added, removed
+}
+
+// NewProblems returns the problems of current not already in previous, so
+// that the same rejected entries aren't reported on every refresh.
+func NewProblems(previous, current []Problem) []Problem {
+	var problems []Problem
+	for _, p := range current {
+		if !slices.Contains(previous, p) {
+			problems = append(problems, p)
+		}
+	}
+	return problems
+}
+
+// DropPercent returns the percentage of the previous roster missing from
+// the current one.
+func DropPercent(previous, current []string) float64 {
+	if len(previous) == 0 {
+		return 0
+	}
+	_, removed := Diff(previous, current)
+	return 100 * float64(len(removed)) / float64(len(previous))
+}
+
+// Empty reports whether there is nothing worth announcing.
+func (c Changes) Empty() bool {
+	return len(c.Added) == 0 && len(c.Removed) == 0 && len(c.Problems) == 0
+}
+
+// String formats the changes as a short Discord message.
+func (c Changes) String() string {
+	var sb strings.Builder
+	if len(c.Added) > 0 {
+		sb.WriteString(fmt.Sprintf("**Added (%d):** %s\n", len(c.Added), joinLimited(c.Added)))
+	}
+	if len(c.Removed) > 0 {
+		sb.WriteString(fmt.Sprintf("**Removed (%d):** %s\n", len(c.Removed), joinLimited(c.Removed)))
+	}
+	if len(c.Problems) > 0 {
+		sb.WriteString(fmt.Sprintf("**Rejected entries (%d):**\n", len(c.Problems)))
+		for i, p := range c.Problems {
+			if i == maxListed {
+				sb.WriteString(fmt.Sprintf("- ... and %d more\n", len(c.Problems)-maxListed))
+				break
+			}
+			sb.WriteString(fmt.Sprintf("- `%s` %s\n", p.Entry, p.Reason))
+		}
+	}
+	return sb.String()
+}
+
+func joinLimited(calls []string) string {
+	if len(calls) <= maxListed {
+		return strings.Join(calls, ", ")
+	}
+	return fmt.Sprintf("%s and %d more", strings.Join(calls[:maxListed], ", "), len(calls)-maxListed)
+}
+
+func isASCII(s string) bool {
+	for _, r := range s {
+		if r > unicode.MaxASCII {
+			return false
+		}
+	}
+	return true
+}
+
+// groupNamePattern matches the names allowed for a group.
+var groupNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// Source is a file or URL providing the callsigns of a group.
+type Source struct {
+	Group    string
+	Location string
+}
+
+// ParseSource parses a "group=location" value. Values without a valid group
+// name prefix (e.g. URLs with query parameters) belong to the DefaultGroup.
+func ParseSource(value string) Source {
+	if name, location, found := strings.Cut(value, "="); found && groupNamePattern.MatchString(name) {
+		return Source{Group: name, Location: location}
+	}
+	return Source{Group: DefaultGroup, Location: value}
+}
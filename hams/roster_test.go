@@ -0,0 +1,149 @@
+package hams
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	input := []string{
+		"KN6YUH",
+		" ak6eu ",
+		"KN6YUH",       // exact duplicate, merged silently
+		"kn6yuh",       // duplicate differing only in case
+		"4O/SQ9MDF/P",  // prefix and portable suffix
+		"NOT A CALL",   // whitespace inside
+		"K6P\u041eTA",  // Cyrillic O
+		"W6SOTA\u200b", // zero-width space
+		"12345",        // no letters
+		"",             // empty
+	}
+
+	valid, problems := Validate(input)
+
+	expected := []string{"KN6YUH", "AK6EU", "4O/SQ9MDF/P"}
+	if !slices.Equal(valid, expected) {
+		t.Errorf("Validate valid mismatch. Want %v, got %v", expected, valid)
+	}
+
+	reasons := make(map[string]string)
+	for _, p := range problems {
+		reasons[p.Entry] = p.Reason
+	}
+	if len(problems) != 6 {
+		t.Errorf("Expected 6 problems, got %d: %v", len(problems), problems)
+	}
+	if !strings.HasPrefix(reasons["kn6yuh"], "duplicate") {
+		t.Errorf("Case duplicate not reported: %q", reasons["kn6yuh"])
+	}
+	if !strings.Contains(reasons["K6P\u041eTA"], "non-ASCII") {
+		t.Errorf("Non-ASCII entry not reported: %q", reasons["K6P\u041eTA"])
+	}
+	if reasons["NOT A CALL"] != "not a valid callsign" {
+		t.Errorf("Malformed entry not reported: %q", reasons["NOT A CALL"])
+	}
+}
+
+func TestDiff(t *testing.T) {
+	previous := []string{"A1A", "B2B", "C3C"}
+	current := []string{"B2B", "C3C", "D4D"}
+
+	added, removed := Diff(previous, current)
+	if !slices.Equal(added, []string{"D4D"}) {
+		t.Errorf("Added mismatch: %v", added)
+	}
+	if !slices.Equal(removed, []string{"A1A"}) {
+		t.Errorf("Removed mismatch: %v", removed)
+	}
+
+	if got := DropPercent(previous, current); int(got) != 33 {
+		t.Errorf("Expected 33%% drop, got %f", got)
+	}
+	if got := DropPercent(nil, current); got != 0 {
+		t.Errorf("Expected no drop for the first load, got %f", got)
+	}
+}
+
+func TestNewProblems(t *testing.T) {
+	previous := []Problem{{Entry: "NOT A CALL", Reason: "not a valid callsign"}}
+	current := []Problem{{Entry: "NOT A CALL", Reason: "not a valid callsign"}, {Entry: "K6POTA ", Reason: "duplicate of \"K6POTA\""}}
+	if got := NewProblems(previous, current); len(got) != 1 || got[0].Entry != "K6POTA " {
+		t.Errorf("NewProblems() = %v", got)
+	}
+	if got := NewProblems(current, previous); len(got) != 0 {
+		t.Errorf("Fixed problems reported: %v", got)
+	}
+}
+
+func TestChangesString(t *testing.T) {
+	if !(Changes{}).Empty() {
+		t.Error("Empty changes not reported as empty")
+	}
+
+	many := make([]string, maxListed+5)
+	for i := range many {
+		many[i] = "K6ABC"
+	}
+	msg := Changes{Added: many, Removed: []string{"W6XYZ"}}.String()
+	if !strings.Contains(msg, "and 5 more") {
+		t.Errorf("Long list not truncated: %s", msg)
+	}
+	if !strings.Contains(msg, "**Removed (1):** W6XYZ") {
+		t.Errorf("Removed section missing: %s", msg)
+	}
+}
+
+func TestParseSource(t *testing.T) {
+	tests := []struct {
+		value string
+		want  Source
+	}{
+		{"members.txt", Source{Group: DefaultGroup, Location: "members.txt"}},
+		{"friends=friends.txt", Source{Group: "friends", Location: "friends.txt"}},
+		{"https://example.com/pub?output=csv", Source{Group: DefaultGroup, Location: "https://example.com/pub?output=csv"}},
+		{"dx=https://example.com/pub?output=csv", Source{Group: "dx", Location: "https://example.com/pub?output=csv"}},
+	}
+	for _, tt := range tests {
+		if got := ParseSource(tt.value); got != tt.want {
+			t.Errorf("ParseSource(%q) = %+v, want %+v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestGroups(t *testing.T) {
+	SetGroups([]Group{
+		{Name: "members", CallSigns: []string{"KN6YUH", "AK6EU"}},
+		{Name: "friends", CallSigns: []string{"AK6EU", "W6SOTA"}},
+	})
+
+	if got := GetCallSigns(); !slices.Equal(got, []string{"KN6YUH", "AK6EU", "W6SOTA"}) {
+		t.Errorf("GetCallSigns mismatch: %v", got)
+	}
+	if got := GroupsOf("ak6eu"); !slices.Equal(got, []string{"members", "friends"}) {
+		t.Errorf("GroupsOf mismatch: %v", got)
+	}
+	if got := GroupsOf("N0CALL"); got != nil {
+		t.Errorf("Unknown callsign has groups: %v", got)
+	}
+
+	SetCallSigns([]string{"K6POTA"})
+	if got := GetGroups(); len(got) != 1 || got[0].Name != DefaultGroup {
+		t.Errorf("SetCallSigns should use the default group, got %v", got)
+	}
+}
+
+func TestBaseCall(t *testing.T) {
+	tests := map[string]string{
+		"KN6YUH":      "KN6YUH",
+		"kn6yuh/p":    "KN6YUH",
+		"W6/KN6YUH/P": "KN6YUH",
+		"KN6YUH-#":    "KN6YUH",
+		"":            "",
+	}
+	for input, want := range tests {
+		if got := BaseCall(input); got != want {
+			t.Errorf("BaseCall(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
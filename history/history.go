@@ -0,0 +1,260 @@
+// This package archives the spots of the tracked callsigns, so that they can
+// be queried by callsign, reference, program and time range after the POTA
+// and SOTA APIs have forgotten them. The archive is an append-only file with
+// one JSON spot per line, indexed in memory when loaded.
+package history
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/PAARA-org/PAARAbot/hams"
+)
+
+// Entry is an archived spot.
+type Entry struct {
+	ID        string    `json:"id"`
+	Time      time.Time `json:"time"`
+	Program   string    `json:"program"`
+	Callsign  string    `json:"callsign"`
+	Reference string    `json:"reference"`
+	// References lists all the references of a multi-park activation.
+	References []string `json:"references,omitempty"`
+	Name       string   `json:"name,omitempty"`
+	Hz         int64    `json:"hz,omitempty"`
+	Band       string   `json:"band,omitempty"`
+	Mode       string   `json:"mode,omitempty"`
+	Spotter    string   `json:"spotter,omitempty"`
+	Comments   string   `json:"comments,omitempty"`
+	Points     int      `json:"points,omitempty"`
+}
+
+// Refs returns the references of the entry.
+func (e Entry) Refs() []string {
+	if len(e.References) == 0 {
+		return []string{e.Reference}
+	}
+	return e.References
+}
+
+// Query selects entries. Empty fields match all the entries.
+type Query struct {
+	Callsign string
+	// BaseCall matches the callsigns ignoring their prefix and suffix, e.g.
+	// KN6YUH matches KN6YUH/P and VE/KN6YUH.
+	BaseCall  string
+	Reference string
+	Program   string
+	// From is inclusive, To is exclusive.
+	From, To time.Time
+}
+
+// Matches reports whether the entry is selected by the query.
+func (q Query) Matches(e Entry) bool {
+	switch {
+	case q.Callsign != "" && !strings.EqualFold(e.Callsign, q.Callsign):
+		return false
+	case q.BaseCall != "" && hams.BaseCall(e.Callsign) != hams.BaseCall(q.BaseCall):
+		return false
+	case q.Program != "" && !strings.EqualFold(e.Program, q.Program):
+		return false
+	case !q.From.IsZero() && e.Time.Before(q.From):
+		return false
+	case !q.To.IsZero() && !e.Time.Before(q.To):
+		return false
+	case q.Reference != "":
+		for _, ref := range e.Refs() {
+			if strings.EqualFold(ref, q.Reference) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// Store is the spot archive. It is safe for concurrent use.
+type Store struct {
+	mu      sync.RWMutex
+	file    *os.File
+	entries []Entry
+	ids     map[string]bool
+	// byCall, byBase and byRef index the entries by callsign, base
+	// callsign and reference.
+	byCall map[string][]int
+	byBase map[string][]int
+	byRef  map[string][]int
+	// oldest is the time of the oldest entry.
+	oldest time.Time
+}
+
+// Open loads the archive stored at path, and appends the new entries to it.
+// A missing file results in an empty archive. Malformed lines, such as a line
+// cut short by a crash, are skipped. If path is empty, the archive is only
+// kept in memory.
+func Open(path string) (*Store, error) {
+	s := &Store{
+		ids:    make(map[string]bool),
+		byCall: make(map[string][]int),
+		byBase: make(map[string][]int),
+		byRef:  make(map[string][]int),
+	}
+	if path == "" {
+		return s, nil
+	}
+
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open history %s: %w", path, err)
+	}
+	skipped, lastByte := 0, byte('\n')
+	reader := bufio.NewReader(file)
+	for line := 1; ; line++ {
+		data, err := reader.ReadBytes('\n')
+		if len(data) > 0 {
+			lastByte = data[len(data)-1]
+			var e Entry
+			if jsonErr := json.Unmarshal(data, &e); jsonErr != nil || e.ID == "" {
+				skipped++
+			} else {
+				s.index(e)
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			file.Close()
+			return nil, fmt.Errorf("failed to read history %s line %d: %w", path, line, err)
+		}
+	}
+	if skipped > 0 {
+		fmt.Printf("Skipped %d malformed lines of history %s\n", skipped, path)
+	}
+	// Entries must start on their own line, even after a partial write
+	if lastByte != '\n' {
+		if _, err := file.WriteString("\n"); err != nil {
+			file.Close()
+			return nil, fmt.Errorf("failed to write history %s: %w", path, err)
+		}
+	}
+	s.file = file
+	return s, nil
+}
+
+// index adds the entry to the in-memory indexes. It must be called with the
+// lock held.
+func (s *Store) index(e Entry) {
+	if s.ids[e.ID] {
+		return
+	}
+	i := len(s.entries)
+	s.entries = append(s.entries, e)
+	s.ids[e.ID] = true
+	if s.oldest.IsZero() || e.Time.Before(s.oldest) {
+		s.oldest = e.Time
+	}
+	call := strings.ToUpper(e.Callsign)
+	s.byCall[call] = append(s.byCall[call], i)
+	base := hams.BaseCall(e.Callsign)
+	s.byBase[base] = append(s.byBase[base], i)
+	for _, ref := range e.Refs() {
+		ref = strings.ToUpper(ref)
+		s.byRef[ref] = append(s.byRef[ref], i)
+	}
+}
+
+// Add archives the entry. It returns false, without error, when an entry
+// with the same ID was already archived.
+func (s *Store) Add(e Entry) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.ids[e.ID] {
+		return false, nil
+	}
+	if s.file != nil {
+		data, err := json.Marshal(e)
+		if err != nil {
+			return false, err
+		}
+		if _, err := s.file.Write(append(data, '\n')); err != nil {
+			return false, fmt.Errorf("failed to write history: %w", err)
+		}
+	}
+	s.index(e)
+	return true, nil
+}
+
+// Find returns the entries selected by the query, oldest first.
+func (s *Store) Find(q Query) []Entry {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	// Use the most selective index available
+	var candidates []int
+	switch {
+	case q.Callsign != "":
+		candidates = s.byCall[strings.ToUpper(q.Callsign)]
+	case q.BaseCall != "":
+		candidates = s.byBase[hams.BaseCall(q.BaseCall)]
+	case q.Reference != "":
+		candidates = s.byRef[strings.ToUpper(q.Reference)]
+	default:
+		candidates = make([]int, len(s.entries))
+		for i := range candidates {
+			candidates[i] = i
+		}
+	}
+
+	var result []Entry
+	for _, i := range candidates {
+		if q.Matches(s.entries[i]) {
+			result = append(result, s.entries[i])
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool { return result[i].Time.Before(result[j].Time) })
+	return result
+}
+
+// Len returns the number of archived entries.
+func (s *Store) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.entries)
+}
+
+// Oldest returns the time of the oldest entry, or the zero time when the
+// archive is empty.
+func (s *Store) Oldest() time.Time {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.oldest
+}
+
+// Persistent reports whether the archive is stored in a file, rather than
+// only kept in memory.
+func (s *Store) Persistent() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.file != nil
+}
+
+// Close closes the archive file.
+func (s *Store) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.file == nil {
+		return nil
+	}
+	err := s.file.Close()
+	s.file = nil
+	return err
+}
@@ -0,0 +1,107 @@
+package history
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	s, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	day := time.Date(2026, 10, 1, 16, 0, 0, 0, time.UTC)
+	entries := []Entry{
+		{ID: "POTA-1", Time: day, Program: "POTA", Callsign: "KN6YUH", Reference: "US-4491", References: []string{"US-4491", "US-4816"}, Mode: "CW"},
+		{ID: "SOTA-2", Time: day.Add(time.Hour), Program: "SOTA", Callsign: "AK6EU", Reference: "W6/CT-001", Points: 4},
+		{ID: "POTA-3", Time: day.AddDate(0, 0, 1), Program: "POTA", Callsign: "kn6yuh", Reference: "US-0001"},
+		{ID: "POTA-6", Time: day.AddDate(0, 0, 2), Program: "POTA", Callsign: "VE7/KN6YUH/P", Reference: "CA-0001"},
+	}
+	for _, e := range entries {
+		if added, err := s.Add(e); !added || err != nil {
+			t.Fatalf("Add(%s) = %v, %v", e.ID, added, err)
+		}
+	}
+	if added, _ := s.Add(entries[0]); added {
+		t.Error("Duplicate entry should not be added")
+	}
+
+	tests := []struct {
+		name  string
+		query Query
+		want  []string
+	}{
+		{"all", Query{}, []string{"POTA-1", "SOTA-2", "POTA-3", "POTA-6"}},
+		{"callsign", Query{Callsign: "KN6YUH"}, []string{"POTA-1", "POTA-3"}},
+		{"base callsign", Query{BaseCall: "kn6yuh/m"}, []string{"POTA-1", "POTA-3", "POTA-6"}},
+		{"n-fer reference", Query{Reference: "us-4816"}, []string{"POTA-1"}},
+		{"program", Query{Program: "SOTA"}, []string{"SOTA-2"}},
+		{"time range", Query{From: day.Add(time.Minute), To: day.AddDate(0, 0, 1)}, []string{"SOTA-2"}},
+		{"callsign and program", Query{Callsign: "KN6YUH", Program: "SOTA"}, nil},
+	}
+	check := func(s *Store) {
+		for _, tt := range tests {
+			got := s.Find(tt.query)
+			if len(got) != len(tt.want) {
+				t.Errorf("%s: got %d entries, want %v", tt.name, len(got), tt.want)
+				continue
+			}
+			for i := range got {
+				if got[i].ID != tt.want[i] {
+					t.Errorf("%s: got %s at %d, want %s", tt.name, got[i].ID, i, tt.want[i])
+				}
+			}
+		}
+	}
+	check(s)
+	if !s.Persistent() || !s.Oldest().Equal(day) {
+		t.Errorf("Persistent() = %v, Oldest() = %v", s.Persistent(), s.Oldest())
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Simulate a crash in the middle of a write
+	f, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	f.WriteString(`{"id":"POTA-4","ti`)
+	f.Close()
+
+	s, err = Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	if s.Len() != 4 {
+		t.Fatalf("Expected 4 entries after reload, got %d", s.Len())
+	}
+	check(s)
+	if got := s.Find(Query{Reference: "US-4491"}); len(got) != 1 || got[0].Mode != "CW" || got[0].Time != day {
+		t.Errorf("Entry not restored: %+v", got)
+	}
+
+	// New entries are appended after the malformed line
+	s.Add(Entry{ID: "POTA-5", Time: day, Program: "POTA", Callsign: "W6XYZ", Reference: "US-0002"})
+	s.Close()
+	s, _ = Open(path)
+	if s.Len() != 5 {
+		t.Errorf("Expected 5 entries, got %d", s.Len())
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	s, err := Open("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Add(Entry{ID: "POTA-1", Program: "POTA", Callsign: "KN6YUH", Reference: "US-4491"})
+	if got := s.Find(Query{Callsign: "KN6YUH"}); len(got) != 1 {
+		t.Errorf("Expected 1 entry, got %d", len(got))
+	}
+	if s.Persistent() {
+		t.Error("Memory archive should not be persistent")
+	}
+}
@@ -0,0 +1,146 @@
+// This package writes the planned activations as an iCalendar (RFC 5545)
+// feed, which calendar applications such as Google Calendar can subscribe
+// to.
+package ical
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/PAARA-org/PAARAbot/plans"
+)
+
+// stamp is the iCalendar format of UTC times.
+const stamp = "20060102T150405Z"
+
+// maxLine is the length at which content lines are folded, in bytes.
+const maxLine = 75
+
+// escape escapes the special characters of a text value.
+func escape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`).Replace(s)
+}
+
+// writer writes content lines, folding them and ending them with CRLF.
+type writer struct {
+	w   *bufio.Writer
+	err error
+}
+
+func (w *writer) line(name, value string) {
+	line := name + ":" + value
+	// Continuation lines start with a space
+	for limit := maxLine; len(line) > limit; limit = maxLine - 1 {
+		// Don't fold in the middle of a UTF-8 sequence
+		cut := limit
+		for cut > 0 && line[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		w.write(line[:cut] + "\r\n ")
+		line = line[cut:]
+	}
+	w.write(line + "\r\n")
+}
+
+func (w *writer) write(s string) {
+	if w.err == nil {
+		_, w.err = w.w.WriteString(s)
+	}
+}
+
+// link returns the web page of a park or summit.
+func link(p plans.Plan) string {
+	if p.Program == "SOTA" {
+		return "https://www.sotadata.org.uk/en/summit/" + p.Reference
+	}
+	return "https://pota.app/#/park/" + p.Reference
+}
+
+// UID returns the unique identifier of the event of a plan. It's stable, so
+// that calendar applications update the events instead of duplicating them.
+func UID(p plans.Plan) string {
+	return strings.ToLower(p.ID) + "@paarabot"
+}
+
+// Write writes the plans as an iCalendar calendar, stamped with now.
+func Write(out io.Writer, list []plans.Plan, now time.Time) error {
+	w := &writer{w: bufio.NewWriter(out)}
+	w.line("BEGIN", "VCALENDAR")
+	w.line("VERSION", "2.0")
+	w.line("PRODID", "-//PAARA//PAARAbot//EN")
+	w.line("CALSCALE", "GREGORIAN")
+	w.line("METHOD", "PUBLISH")
+	w.line("X-WR-CALNAME", "Club activations")
+	for _, p := range list {
+		summary := fmt.Sprintf("%s: %s %s", p.Callsign, p.Program, p.Reference)
+		location := p.Reference
+		if p.Name != "" {
+			summary += " (" + p.Name + ")"
+			location = p.Name + " (" + p.Reference + ")"
+		}
+		var description []string
+		if p.Frequencies != "" {
+			description = append(description, "On "+p.Frequencies+".")
+		}
+		if p.Comments != "" {
+			description = append(description, p.Comments)
+		}
+
+		w.line("BEGIN", "VEVENT")
+		w.line("UID", UID(p))
+		w.line("DTSTAMP", now.UTC().Format(stamp))
+		w.line("DTSTART", p.Start.UTC().Format(stamp))
+		w.line("DTEND", p.End.UTC().Format(stamp))
+		w.line("SUMMARY", escape(summary))
+		w.line("LOCATION", escape(location))
+		if len(description) > 0 {
+			w.line("DESCRIPTION", escape(strings.Join(description, "\n")))
+		}
+		w.line("URL", link(p))
+		w.line("CATEGORIES", p.Program)
+		w.line("END", "VEVENT")
+	}
+	w.line("END", "VCALENDAR")
+	if w.err != nil {
+		return w.err
+	}
+	return w.w.Flush()
+}
+
+// WriteFile writes the calendar to path, replacing it atomically.
+func WriteFile(path string, list []plans.Plan, now time.Time) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to write calendar: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if err := Write(tmp, list, now); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write calendar: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write calendar: %w", err)
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Handler serves the calendar of the upcoming plans of the store.
+func Handler(store *plans.Store) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		now := time.Now()
+		w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+		w.Header().Set("Content-Disposition", `inline; filename="activations.ics"`)
+		if err := Write(w, store.Upcoming(now), now); err != nil {
+			fmt.Println("Error writing calendar:", err)
+		}
+	})
+}
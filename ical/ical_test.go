@@ -0,0 +1,96 @@
+package ical
+
+import (
+	"io"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PAARA-org/PAARAbot/plans"
+)
+
+var now = time.Date(2026, 10, 19, 21, 0, 0, 0, time.UTC)
+
+var testPlans = []plans.Plan{
+	{ID: "SOTA-123", Program: "SOTA", Callsign: "KN6YUH", Reference: "W6/CT-001", Name: "Mount Umunhum",
+		Start: now.Add(19 * time.Hour), End: now.Add(21 * time.Hour), Frequencies: "20m CW, 40m CW", Comments: "Weather permitting; bring water"},
+	{ID: "POTA-7", Program: "POTA", Callsign: "AK6EU", Reference: "US-1234",
+		Start: now.Add(24 * time.Hour), End: now.Add(26 * time.Hour)},
+}
+
+func TestWrite(t *testing.T) {
+	var sb strings.Builder
+	if err := Write(&sb, testPlans, now); err != nil {
+		t.Fatal(err)
+	}
+	out := sb.String()
+	for _, want := range []string{
+		"BEGIN:VCALENDAR\r\nVERSION:2.0\r\n",
+		"UID:sota-123@paarabot\r\n",
+		"DTSTAMP:20261019T210000Z\r\n",
+		"DTSTART:20261020T160000Z\r\nDTEND:20261020T180000Z\r\n",
+		"SUMMARY:KN6YUH: SOTA W6/CT-001 (Mount Umunhum)\r\n",
+		"LOCATION:Mount Umunhum (W6/CT-001)\r\n",
+		"URL:https://www.sotadata.org.uk/en/summit/W6/CT-001\r\n",
+		"UID:pota-7@paarabot\r\n",
+		"LOCATION:US-1234\r\n",
+		"END:VCALENDAR\r\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Missing %q in:\n%s", want, out)
+		}
+	}
+	// The description is escaped and folded
+	unfolded := strings.ReplaceAll(out, "\r\n ", "")
+	if !strings.Contains(unfolded, `DESCRIPTION:On 20m CW\, 40m CW.\nWeather permitting\; bring water`+"\r\n") {
+		t.Errorf("Unexpected description in:\n%s", unfolded)
+	}
+	for _, line := range strings.Split(out, "\r\n") {
+		if len(line) > maxLine {
+			t.Errorf("Line isn't folded: %q", line)
+		}
+	}
+}
+
+func TestFold(t *testing.T) {
+	var sb strings.Builder
+	Write(&sb, []plans.Plan{{ID: "POTA-1", Comments: strings.Repeat("é", 100)}}, now)
+	for _, line := range strings.Split(sb.String(), "\r\n") {
+		if len(line) > maxLine {
+			t.Errorf("Line isn't folded: %q", line)
+		}
+		if strings.ToValidUTF8(line, "?") != line {
+			t.Errorf("Line folded within a character: %q", line)
+		}
+	}
+}
+
+func TestWriteFileAndHandler(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "activations.ics")
+	if err := WriteFile(path, testPlans, now); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil || !strings.Contains(string(data), "UID:pota-7@paarabot") {
+		t.Errorf("Unexpected file: %s, %v", data, err)
+	}
+
+	store, _ := plans.Load("")
+	start := time.Now().Add(-4 * time.Hour)
+	store.Update("SOTA", []plans.Plan{
+		{ID: "SOTA-1", Program: "SOTA", Start: start, End: start.Add(2 * time.Hour)},
+		{ID: "SOTA-2", Program: "SOTA", Start: start.AddDate(0, 0, 1), End: start.AddDate(0, 0, 1).Add(2 * time.Hour)},
+	}, start.Add(-time.Hour))
+	rec := httptest.NewRecorder()
+	Handler(store).ServeHTTP(rec, httptest.NewRequest("GET", "/activations.ics", nil))
+	body, _ := io.ReadAll(rec.Result().Body)
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/calendar") {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	if strings.Contains(string(body), "UID:sota-1@") || !strings.Contains(string(body), "UID:sota-2@") {
+		t.Errorf("Only the upcoming plans should be served:\n%s", body)
+	}
+}
@@ -4,26 +4,88 @@ import (
 	"flag"
 	"fmt"
 	"log"
+	"net/http"
 	"os"
+	"slices"
+	"strings"
 	"time"
 
+	"github.com/PAARA-org/PAARAbot/bandplan"
 	"github.com/PAARA-org/PAARAbot/bot"
 	"github.com/PAARA-org/PAARAbot/buildinfo"
+	"github.com/PAARA-org/PAARAbot/filter"
 	"github.com/PAARA-org/PAARAbot/hams"
+	"github.com/PAARA-org/PAARAbot/history"
+	"github.com/PAARA-org/PAARAbot/ical"
+	"github.com/PAARA-org/PAARAbot/notify"
+	"github.com/PAARA-org/PAARAbot/plans"
+	"github.com/PAARA-org/PAARAbot/pota"
+	"github.com/PAARA-org/PAARAbot/roles"
 	"github.com/PAARA-org/PAARAbot/sota"
+	"github.com/PAARA-org/PAARAbot/watch"
 )
 
 func main() {
 	// Defining all the flags needed by the program
-	hamfile := flag.String("hamfile", "", "File containing the list of ham callsigns to check for activations.")
-	csvURL := flag.String("csvURL", "", "URL to a CSV file containing ham callsigns (e.g. Google Sheet export link).")
+	var sources []rosterSource
+	flag.Var(sourceFlag{sources: &sources}, "hamfile", "File containing the list of ham callsigns to check for activations. Can be repeated, and prefixed with a group name (e.g. friends=friends.txt).")
+	flag.Var(sourceFlag{sources: &sources, web: true}, "csvURL", "URL to a CSV file containing ham callsigns (e.g. Google Sheet export link). Can be repeated, and prefixed with a group name.")
+	groupChannels := mapFlag{}
+	flag.Var(groupChannels, "groupChannel", "Routes a group's posts to a Discord channel, as group=channelID or group/PROGRAM=channelID. Can be repeated.")
+	groupFormats := mapFlag{}
+	flag.Var(groupFormats, "groupFormat", "Message format of a group's posts, as group=format or group/PROGRAM=format. Can be repeated.")
+	var digests listFlag
+	flag.Var(&digests, "digest", "Posts a digest of the activations to a channel, as channelID=daily or channelID=weekly, optionally followed by @ and a cron schedule (e.g. 1234=weekly@0 9 * * 1). Can be repeated.")
 	refreshInterval := flag.Duration("refreshInterval", 8*time.Hour, "How often to refresh the callsigns from the CSV URL.")
+	maxRosterDrop := flag.Float64("maxRosterDrop", 25, "Maximum percentage of callsigns a refresh may remove before the previous list is kept.")
+	adminChannelID := flag.String("adminChannelID", "", "Optional Discord channel ID receiving roster refresh reports and alerts.")
 	sotacsv := flag.String("sotacsv", "", "CSV file containing mapping from peak to park.")
+	sotacsvURL := flag.String("sotacsvURL", "", "Optional URL of the CSV mapping peaks to parks, downloaded on a schedule and cached to -sotacsv (default \"sota_pota.csv\").")
+	summitscsv := flag.String("summitscsv", "", "Optional copy of the SOTA summits list (summitslist.csv).")
+	summitsURL := flag.String("summitsURL", "", "Optional URL of the SOTA summits list, downloaded on a schedule and cached to -summitscsv (default \"summitslist.csv\").")
+	parkscsv := flag.String("parkscsv", "", "Optional copy of the POTA parks list (all_parks_ext.csv), used by the /park command.")
+	sotaRefreshInterval := flag.Duration("sotaRefreshInterval", 24*time.Hour, "How often to download the SOTA lists from -sotacsvURL and -summitsURL.")
 	token := flag.String("token", "", "Discord bot token")
+	webhookURL := flag.String("webhookURL", "", "Discord webhook URL to post the spots to, instead of running a bot with -token. The commands are unavailable in this mode.")
+	webhookUsernames := mapFlag{}
+	flag.Var(webhookUsernames, "webhookUsername", "Name of the webhook posts of a program, as PROGRAM=name (e.g. POTA=\"POTA spots\"). Can be repeated.")
+	webhookAvatars := mapFlag{}
+	flag.Var(webhookAvatars, "webhookAvatar", "Avatar URL of the webhook posts of a program, as PROGRAM=url. Can be repeated.")
+	slackWebhookURL := flag.String("slackWebhookURL", "", "Optional Slack incoming webhook URL also receiving the posts.")
+	matrixHomeserver := flag.String("matrixHomeserver", "", "Optional Matrix homeserver URL (e.g. https://matrix.org) also receiving the posts, in -matrixRoom.")
+	matrixRoom := flag.String("matrixRoom", "", "Matrix room ID (e.g. !abc:matrix.org) receiving the posts.")
+	matrixToken := flag.String("matrixToken", "", "Access token of the Matrix user posting to -matrixRoom.")
+	telegramToken := flag.String("telegramToken", "", "Optional Telegram bot token, also posting to -telegramChatID.")
+	telegramChatID := flag.String("telegramChatID", "", "Telegram chat ID (e.g. @channel or -100123) receiving the posts.")
+	notifyWebhookURL := flag.String("notifyWebhookURL", "", "Optional URL also receiving the posts as JSON.")
+	ircServer := flag.String("ircServer", "", "Optional IRC server (e.g. irc.libera.chat:6697) also receiving the posts, in -ircChannel, and answering \"!spots CALL\".")
+	ircTLS := flag.Bool("ircTLS", false, "Connect to -ircServer with TLS.")
+	ircPassword := flag.String("ircPassword", "", "Optional password of -ircServer.")
+	ircNick := flag.String("ircNick", "PAARAbot", "Nickname of the bot on IRC.")
+	ircChannel := flag.String("ircChannel", "", "IRC channel (e.g. #paara) receiving the posts.")
+	ircPlain := flag.Bool("ircPlain", false, "Post to IRC without colors and bold.")
+	ircFloodDelay := flag.Duration("ircFloodDelay", 2*time.Second, "Minimum delay between two lines sent to IRC.")
+	ircCommandCooldown := flag.Duration("ircCommandCooldown", 30*time.Second, "How often each IRC user can ask for \"!spots CALL\".")
 	potaChannelID := flag.String("potaChannelID", "", "POTA channel ID from Discord.")
 	sotaChannelID := flag.String("sotaChannelID", "", "SOTA channel ID from Discord.")
 	spotCheckInterval := flag.Duration("spotCheckInterval", 2*time.Minute, "How often to check for new spots")
 	postThrottleTime := flag.Duration("postThrottleTime", 4*time.Hour, "How often to re-post the same spot.")
+	huntersChannelID := flag.String("huntersChannelID", "", "Optional Discord channel ID of the hunters feed, listing the parks and summits chased by the tracked callsigns.")
+	huntersSummary := flag.String("huntersSummary", "", "Local time (e.g. 21:00) of the daily hunters summary. When empty, hunts are posted as they're spotted.")
+	guildID := flag.String("guildID", "", "Optional Discord server ID to register the slash commands to, making them available immediately.")
+	ituRegion := flag.Int("ituRegion", 2, "ITU region (1, 2 or 3) used to tell whether spots are within the amateur bands.")
+	filterfile := flag.String("filterfile", "", "Optional file with the allow/deny rules deciding which spots are posted.")
+	rolefile := flag.String("rolefile", "", "Optional file mapping Discord roles to the band, mode, program or SOTA points of the spots mentioning them.")
+	historyfile := flag.String("historyfile", "history.jsonl", "File archiving the spots of the tracked callsigns.")
+	plansfile := flag.String("plansfile", "plans.json", "File storing the planned activations of the tracked callsigns, and the messages announcing them.")
+	planCheckInterval := flag.Duration("planCheckInterval", 15*time.Minute, "How often to check the SOTA alerts and the POTA scheduled activations of the tracked callsigns.")
+	reminderLead := flag.Duration("reminderLead", 30*time.Minute, "How long before a planned activation to remind the channel.")
+	threads := flag.Bool("threads", false, "Start a discussion thread on each activation post, where its QSYs are posted.")
+	qrtTimeout := flag.Duration("qrtTimeout", time.Hour, "How long after its last spot an activation is considered over, and its thread archived.")
+	scheduledEvents := flag.Bool("scheduledEvents", false, "Create Discord scheduled events for the planned activations of the tracked callsigns.")
+	icsAddr := flag.String("icsAddr", "", "Optional address (e.g. :8080) serving the iCalendar feed of the planned activations at /activations.ics.")
+	icsfile := flag.String("icsfile", "", "Optional file where the iCalendar feed of the planned activations is written.")
+	watchfile := flag.String("watchfile", "watchlists.json", "File storing the personal watchlists of the Discord users.")
 	versionFlag := flag.Bool("version", false, "Display application build information and exit.")
 
 	// Parse the flags
@@ -39,40 +101,112 @@ func main() {
 		os.Exit(0)
 	}
 
-	var fileCallSigns []string
-	// If the hamfile is specified, parse it.
-	if *hamfile != "" {
+	// Load the filters first, as they can be tested without connecting to Discord
+	var filters filter.Rules
+	if *filterfile != "" {
 		var err error
-		fileCallSigns, err = hams.ParseCallSigns(*hamfile)
+		filters, err = filter.ParseFile(*filterfile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		log.Println("Successfully parsed", len(filters), "filter rules from", *filterfile)
+	}
+
+	// "filter test field=value..." evaluates the filters against a sample spot
+	if flag.NArg() >= 2 && flag.Arg(0) == "filter" && flag.Arg(1) == "test" {
+		os.Exit(testFilter(filters, flag.Args()[2:]))
+	}
+
+	// Groups are ordered by their first appearance on the command line, which
+	// sets their precedence when a callsign belongs to several groups.
+	var groupOrder []string
+	hasWebSources := false
+	for _, src := range sources {
+		if !slices.Contains(groupOrder, src.Group) {
+			groupOrder = append(groupOrder, src.Group)
+		}
+		hasWebSources = hasWebSources || src.web
+	}
+
+	fileCallSigns := make(map[string][]string)
+	// If hamfiles are specified, parse them.
+	for _, src := range sources {
+		if src.web {
+			continue
+		}
+		calls, err := hams.ParseCallSigns(src.Location)
 		if err != nil {
 			log.Fatal(err)
 		} else {
-			log.Println("Successfully parsed ", len(fileCallSigns), "callsigns from ", *hamfile)
+			log.Println("Successfully parsed ", len(calls), "callsigns from ", src.Location, "for group", src.Group)
 		}
+		fileCallSigns[src.Group] = append(fileCallSigns[src.Group], calls...)
 	}
 
+	// The admin channel receives the roster reports, including the initial one
+	bot.AdminChannelID = *adminChannelID
+
 	// Function to refresh and combine callsigns
+	var previousCallSigns []string
+	var reportedProblems []hams.Problem
+	// The last callsigns fetched from each web source, by index in sources
+	fetchedCallSigns := make(map[int][]string)
 	refreshCallSigns := func() {
-		var urlCallSigns []string
-		if *csvURL != "" {
-			var err error
-			urlCallSigns, err = hams.FetchFromWeb(*csvURL)
+		combined := make(map[string][]string)
+		for group, calls := range fileCallSigns {
+			combined[group] = append(combined[group], calls...)
+		}
+		for i, src := range sources {
+			if !src.web {
+				continue
+			}
+			urlCallSigns, err := hams.FetchFromWeb(src.Location)
 			if err != nil {
+				// A small group would go unnoticed by -maxRosterDrop, so the
+				// source keeps its previous callsigns rather than being emptied.
+				urlCallSigns = fetchedCallSigns[i]
 				log.Println("Error fetching callsigns from URL:", err)
+				bot.PostAdmin(fmt.Sprintf("⚠️ Fetching the callsigns of group %s failed, keeping the previous %d callsigns: %v", src.Group, len(urlCallSigns), err))
 			} else {
-				log.Println("Successfully fetched", len(urlCallSigns), "callsigns from URL")
+				log.Println("Successfully fetched", len(urlCallSigns), "callsigns from URL for group", src.Group)
+				fetchedCallSigns[i] = urlCallSigns
 			}
+			combined[src.Group] = append(combined[src.Group], urlCallSigns...)
+		}
+
+		var groups []hams.Group
+		var all []string
+		var problems []hams.Problem
+		for _, name := range groupOrder {
+			valid, p := hams.Validate(combined[name])
+			groups = append(groups, hams.Group{Name: name, CallSigns: valid})
+			all = append(all, valid...)
+			problems = append(problems, p...)
 		}
+		all = hams.Unique(all)
 
-		// Combine callsigns
-		combined := make([]string, 0, len(fileCallSigns)+len(urlCallSigns))
-		combined = append(combined, fileCallSigns...)
-		combined = append(combined, urlCallSigns...)
+		// Refuse refreshes dropping a large part of the roster, as that's most
+		// likely a broken sheet rather than members leaving the club.
+		if drop := hams.DropPercent(previousCallSigns, all); drop > *maxRosterDrop {
+			bot.PostAdmin(fmt.Sprintf("⚠️ Roster refresh would remove %.0f%% of the %d callsigns (limit %.0f%%), keeping the previous list.\n%s",
+				drop, len(previousCallSigns), *maxRosterDrop, hams.Changes{Problems: problems}))
+			return
+		}
+
+		// The rejected entries are reported once, not on every refresh
+		changes := hams.Changes{Problems: hams.NewProblems(reportedProblems, problems)}
+		if previousCallSigns != nil {
+			changes.Added, changes.Removed = hams.Diff(previousCallSigns, all)
+		}
 
-		uniqueCombined := hams.Unique(combined)
+		hams.SetGroups(groups)
+		previousCallSigns = all
+		reportedProblems = problems
+		log.Println("Total callsigns loaded:", len(all), "in", len(groups), "groups")
 
-		hams.SetCallSigns(uniqueCombined)
-		log.Println("Total callsigns loaded:", len(uniqueCombined))
+		if !changes.Empty() {
+			bot.PostAdmin(fmt.Sprintf("Roster refreshed, %d callsigns loaded.\n%s", len(all), changes))
+		}
 	}
 
 	// Initial load
@@ -84,7 +218,7 @@ func main() {
 	}
 
 	// Start refresher if URL is present
-	if *csvURL != "" {
+	if hasWebSources {
 		go func() {
 			ticker := time.NewTicker(*refreshInterval)
 			for range ticker.C {
@@ -94,14 +228,175 @@ func main() {
 		}()
 	}
 
+	// In webhook mode, the channels default to the webhook.
+	if *token == "" && *webhookURL != "" {
+		if err := bot.ValidWebhook(*webhookURL); err != nil {
+			log.Fatal(err)
+		}
+		if *potaChannelID == "" {
+			*potaChannelID = *webhookURL
+		}
+		if *sotaChannelID == "" {
+			*sotaChannelID = *webhookURL
+		}
+		if *threads || *scheduledEvents {
+			log.Fatal("-threads and -scheduledEvents need a bot, please set -token instead of -webhookURL.")
+		}
+	}
+
+	// The other chat services receive the same posts as Discord, from their
+	// own queue. The IRC notifier queues its lines itself.
+	if *slackWebhookURL != "" {
+		bot.Notifiers = append(bot.Notifiers, notify.NewQueue(notify.Slack{WebhookURL: *slackWebhookURL}))
+	}
+	if *matrixHomeserver != "" {
+		if *matrixRoom == "" || *matrixToken == "" {
+			log.Fatal("-matrixHomeserver needs -matrixRoom and -matrixToken.")
+		}
+		bot.Notifiers = append(bot.Notifiers, notify.NewQueue(notify.Matrix{Homeserver: *matrixHomeserver, RoomID: *matrixRoom, AccessToken: *matrixToken}))
+	}
+	if *telegramToken != "" {
+		if *telegramChatID == "" {
+			log.Fatal("-telegramToken needs -telegramChatID.")
+		}
+		bot.Notifiers = append(bot.Notifiers, notify.NewQueue(notify.Telegram{Token: *telegramToken, ChatID: *telegramChatID}))
+	}
+	if *notifyWebhookURL != "" {
+		bot.Notifiers = append(bot.Notifiers, notify.NewQueue(notify.Webhook{URL: *notifyWebhookURL}))
+	}
+
+	if *ircServer != "" {
+		if *ircChannel == "" {
+			log.Fatal("-ircServer needs -ircChannel.")
+		}
+		irc := &notify.IRC{
+			Server:          *ircServer,
+			TLS:             *ircTLS,
+			Password:        *ircPassword,
+			Nick:            *ircNick,
+			Channel:         *ircChannel,
+			Plain:           *ircPlain,
+			Spots:           bot.RecentSpots,
+			FloodDelay:      *ircFloodDelay,
+			CommandCooldown: *ircCommandCooldown,
+		}
+		irc.Start()
+		defer irc.Close()
+		bot.Notifiers = append(bot.Notifiers, irc)
+	}
+
 	// Check that all the Discord variables are set
-	if *token == "" || *potaChannelID == "" || *sotaChannelID == "" {
-		log.Fatal("Bot token, POTA or SOTA channel IDs weren't provided. Please rerun the program with these flags set or use -help for more info.")
+	if (*token == "" && *webhookURL == "") || *potaChannelID == "" || *sotaChannelID == "" {
+		log.Fatal("Bot token (or webhook URL), POTA or SOTA channel IDs weren't provided. Please rerun the program with these flags set or use -help for more info.")
+	}
+
+	// Downloaded SOTA lists are cached on disk, and the cached copy is used
+	// until the next successful download.
+	if *sotacsvURL != "" && *sotacsv == "" {
+		*sotacsv = "sota_pota.csv"
+	}
+	if *summitsURL != "" && *summitscsv == "" {
+		*summitscsv = "summitslist.csv"
+	}
+
+	// These are optional flags
+	if _, err := os.Stat(*sotacsv); *sotacsv != "" && (err == nil || *sotacsvURL == "") {
+		sota.SetMappings(sota.ParseSotaCSV(*sotacsv))
+	}
+	if _, err := os.Stat(*summitscsv); *summitscsv != "" && (err == nil || *summitsURL == "") {
+		summits, err := sota.ParseSummitsFile(*summitscsv)
+		if err != nil {
+			log.Fatal(err)
+		}
+		log.Println("Successfully parsed", len(summits), "summits from", *summitscsv)
+		sota.SetSummits(summits)
+	}
+
+	if *parkscsv != "" {
+		parks, err := pota.ParseParksFile(*parkscsv)
+		if err != nil {
+			log.Fatal(err)
+		}
+		log.Println("Successfully parsed", len(parks), "parks from", *parkscsv)
+		pota.SetParks(parks)
+	}
+
+	// Function to download the SOTA lists, keeping the previous ones on failure
+	refreshSotaLists := func() {
+		if *sotacsvURL != "" {
+			if n, err := sota.RefreshMappings(*sotacsvURL, *sotacsv); err != nil {
+				bot.PostAdmin(fmt.Sprintf("⚠️ Peak to park mappings download failed, keeping the previous %d mappings: %v", sota.MappingsCount(), err))
+			} else {
+				log.Println("Successfully fetched", n, "peak to park mappings from", *sotacsvURL)
+			}
+		}
+		if *summitsURL != "" {
+			if n, err := sota.RefreshSummits(*summitsURL, *summitscsv); err != nil {
+				bot.PostAdmin(fmt.Sprintf("⚠️ Summits list download failed, keeping the previous %d summits: %v", sota.SummitsCount(), err))
+			} else {
+				log.Println("Successfully fetched", n, "summits from", *summitsURL)
+			}
+		}
+	}
+
+	// The lists are large, so they're downloaded in the background
+	if *sotacsvURL != "" || *summitsURL != "" {
+		go func() {
+			refreshSotaLists()
+			ticker := time.NewTicker(*sotaRefreshInterval)
+			for range ticker.C {
+				log.Println("Refreshing the SOTA lists...")
+				refreshSotaLists()
+			}
+		}()
+	}
+
+	if _, err := time.Parse("15:04", *huntersSummary); *huntersSummary != "" && err != nil {
+		log.Fatal("Invalid -huntersSummary time, expected HH:MM: ", err)
+	}
+
+	if *ituRegion < 1 || *ituRegion > 3 {
+		log.Fatal("The ITU region must be 1, 2 or 3.")
 	}
 
 	// This is an optional flag
-	if *sotacsv != "" {
-		sota.SotaPotaMappings = sota.ParseSotaCSV(*sotacsv)
+	if *rolefile != "" {
+		rules, err := roles.ParseFile(*rolefile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		log.Println("Successfully parsed", len(rules), "role rules from", *rolefile)
+		bot.RoleRules = rules
+	}
+
+	watchlists, err := watch.Load(*watchfile)
+	if err != nil {
+		log.Fatal(err)
+	}
+	watchlists.DefaultThrottle = *postThrottleTime
+	bot.Watchlists = watchlists
+
+	archive, err := history.Open(*historyfile)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer archive.Close()
+	log.Println("Loaded", archive.Len(), "spots from", *historyfile)
+	bot.History = archive
+
+	planned, err := plans.Load(*plansfile)
+	if err != nil {
+		log.Fatal(err)
+	}
+	bot.Plans = planned
+
+	if *icsAddr != "" {
+		mux := http.NewServeMux()
+		mux.Handle("/activations.ics", ical.Handler(planned))
+		go func() {
+			log.Fatal(http.ListenAndServe(*icsAddr, mux))
+		}()
+		log.Println("Serving the activations calendar on", *icsAddr)
 	}
 
 	// Set the bot's public variables with the values collected through the flags.
@@ -110,7 +405,60 @@ func main() {
 	bot.SotaChannelID = *sotaChannelID
 	bot.RunInterval = *spotCheckInterval
 	bot.ThrottleTime = *postThrottleTime
+	bot.PlanCheckInterval = *planCheckInterval
+	bot.ReminderLead = *reminderLead
+	bot.ScheduledEvents = *scheduledEvents
+	bot.Threads = *threads
+	for program, name := range webhookUsernames {
+		bot.WebhookUsernames[strings.ToUpper(program)] = name
+	}
+	for program, url := range webhookAvatars {
+		bot.WebhookAvatars[strings.ToUpper(program)] = url
+	}
+	bot.QRTTimeout = *qrtTimeout
+	bot.CalendarFile = *icsfile
+	bot.GuildID = *guildID
+	bot.HuntersChannelID = *huntersChannelID
+	bot.HuntersSummaryTime = *huntersSummary
+	bot.Region = bandplan.Region(*ituRegion)
+	bot.GroupChannels = groupChannels
+	bot.Filters = filters
+	for _, value := range digests {
+		d, err := bot.ParseDigest(value)
+		if err != nil {
+			log.Fatal(err)
+		}
+		bot.Digests = append(bot.Digests, d)
+	}
+	for key, value := range groupFormats {
+		tmpl, err := bot.ParseFormat(value)
+		if err != nil {
+			log.Fatalf("Invalid format for %s: %v", key, err)
+		}
+		bot.GroupFormats[key] = tmpl
+	}
 
 	// Let's run the bot!
 	bot.Run()
 }
+
+// testFilter prints the decision of the filters for the sample spot built
+// from the field=value arguments, and returns the process exit code.
+func testFilter(filters filter.Rules, args []string) int {
+	spot, err := filter.ParseSpot(args)
+	if err != nil {
+		fmt.Println(err)
+		return 2
+	}
+	allow, rule := filters.Evaluate(spot)
+	decision := "DENIED"
+	if allow {
+		decision = "ALLOWED"
+	}
+	if rule == nil {
+		fmt.Printf("%s: no rule matched %+v\n", decision, spot)
+	} else {
+		fmt.Printf("%s by rule on line %d: %s\n", decision, rule.Line, rule.Text)
+	}
+	return 0
+}
@@ -0,0 +1,449 @@
+package notify
+
+import (
+	"bufio"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// IRC posts to an IRC channel, as one line per line of the messages, and
+// answers "!spots CALL" in the channel or in private.
+//
+// The client reconnects when the connection is lost, and sends at most one
+// line every FloodDelay to avoid being kicked by the server. The lines
+// posted while disconnected are kept, up to ircQueueSize. The answers to
+// the commands have their own smaller queue, so that they can't push out
+// the posts, and each nick can only send a command every CommandCooldown.
+type IRC struct {
+	// Server is the address of the server, e.g. irc.libera.chat:6697.
+	Server string
+	TLS    bool
+	// Password is the optional server password.
+	Password string
+	Nick     string
+	Channel  string
+	// Plain disables the mIRC colors and bold.
+	Plain bool
+	// Spots answers "!spots CALL", if set.
+	Spots func(callsign string) string
+	// FloodDelay defaults to 2s.
+	FloodDelay time.Duration
+	// ReconnectDelay is the first delay before reconnecting, doubling up to
+	// 5 minutes. It defaults to 10s.
+	ReconnectDelay time.Duration
+	// CommandCooldown defaults to 30s.
+	CommandCooldown time.Duration
+
+	mu      sync.Mutex
+	queue   chan string
+	replies chan string
+	// lookup holds the command being answered, as looking up the spots may
+	// query the POTA and SOTA APIs.
+	lookup   chan struct{}
+	commands map[string]time.Time
+	conn     net.Conn
+	done     chan struct{}
+}
+
+const (
+	// ircQueueSize is the number of lines waiting to be sent.
+	ircQueueSize = 100
+	// ircRepliesSize is the number of answers waiting to be sent.
+	ircRepliesSize = 20
+	// ircLineLength keeps the lines below the 512 bytes limit of the
+	// protocol, including the prefix added by the server.
+	ircLineLength = 400
+	maxReconnect  = 5 * time.Minute
+	ircTimeout    = 30 * time.Second
+	// ircPingInterval is how long the connection may be idle before the
+	// server is pinged, to detect lost connections.
+	ircPingInterval = 4 * time.Minute
+)
+
+// mIRC formatting codes
+const (
+	ircBold   = "\x02"
+	ircColor  = "\x03"
+	ircStrike = "\x1e"
+	ircReset  = "\x0f"
+)
+
+// programColors are the mIRC colors of the programs.
+var programColors = map[string]string{
+	"POTA": "03", // green
+	"SOTA": "07", // orange
+}
+
+func (c *IRC) Name() string { return "IRC" }
+
+// Start connects to the server in the background, until Close.
+func (c *IRC) Start() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.init()
+	go c.run()
+}
+
+// Close disconnects from the server.
+func (c *IRC) Close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.init()
+	select {
+	case <-c.done:
+		return
+	default:
+	}
+	close(c.done)
+	if c.conn != nil {
+		c.conn.Write([]byte("QUIT :73\r\n"))
+		c.conn.Close()
+	}
+}
+
+// init creates the channels, with c.mu held.
+func (c *IRC) init() {
+	if c.queue == nil {
+		c.queue = make(chan string, ircQueueSize)
+		c.replies = make(chan string, ircRepliesSize)
+		c.lookup = make(chan struct{}, 1)
+		c.commands = make(map[string]time.Time)
+		c.done = make(chan struct{})
+	}
+}
+
+// Notify queues the lines of the message to the channel.
+func (c *IRC) Notify(m Message) error {
+	c.mu.Lock()
+	c.init()
+	c.mu.Unlock()
+	prefix := ""
+	if m.Program != "" {
+		prefix = c.format("**[" + m.Program + "]** ")
+		if color, ok := programColors[m.Program]; ok && !c.Plain {
+			prefix = ircColor + color + ircBold + "[" + m.Program + "]" + ircReset + " "
+		}
+	}
+	for _, line := range c.lines(m.Text) {
+		if err := enqueue(c.queue, c.Channel, prefix+line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// lines splits the text into formatted lines, skipping the empty ones.
+func (c *IRC) lines(text string) []string {
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.TrimSpace(sanitize(line)); line != "" {
+			lines = append(lines, c.format(line))
+		}
+	}
+	return lines
+}
+
+// sanitize replaces the control characters of the line with spaces. The
+// spot comments come from any spotter, and a CR would otherwise end the
+// PRIVMSG and send the rest of the line as a command, while the mIRC codes
+// would garble the formatting.
+func sanitize(line string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return ' '
+		}
+		return r
+	}, line)
+}
+
+// format converts the markdown of the line to the mIRC codes, or removes it.
+func (c *IRC) format(line string) string {
+	if c.Plain {
+		return Plain(line)
+	}
+	line = boldPattern.ReplaceAllString(line, ircBold+"$1"+ircBold)
+	return strikePattern.ReplaceAllString(line, ircStrike+"$1"+ircStrike)
+}
+
+// enqueue queues a PRIVMSG, failing when the queue is full.
+func enqueue(queue chan string, target, text string) error {
+	select {
+	case queue <- "PRIVMSG " + target + " :" + truncateLine(text):
+		return nil
+	default:
+		return fmt.Errorf("IRC queue full, dropping %q", text)
+	}
+}
+
+// truncateLine cuts the line to ircLineLength bytes, without splitting a
+// UTF-8 character.
+func truncateLine(line string) string {
+	if len(line) <= ircLineLength {
+		return line
+	}
+	cut := ircLineLength
+	for cut > 0 && line[cut]&0xC0 == 0x80 {
+		cut--
+	}
+	return line[:cut]
+}
+
+// run keeps connecting to the server, until Close.
+func (c *IRC) run() {
+	delay := c.ReconnectDelay
+	if delay <= 0 {
+		delay = 10 * time.Second
+	}
+	backoff := delay
+	for {
+		start := time.Now()
+		err := c.session()
+		select {
+		case <-c.done:
+			return
+		default:
+		}
+		fmt.Println("IRC connection lost, reconnecting in", backoff, ":", err)
+		// A session that lasted resets the backoff
+		if time.Since(start) > maxReconnect {
+			backoff = delay
+		}
+		select {
+		case <-c.done:
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(2*backoff, maxReconnect)
+	}
+}
+
+// session connects to the server, joins the channel and sends the queued
+// lines, until the connection is lost.
+func (c *IRC) session() error {
+	dialer := &net.Dialer{Timeout: ircTimeout}
+	var conn net.Conn
+	var err error
+	if c.TLS {
+		conn, err = tls.DialWithDialer(dialer, "tcp", c.Server, nil)
+	} else {
+		conn, err = dialer.Dial("tcp", c.Server)
+	}
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	select {
+	case <-c.done:
+		c.mu.Unlock()
+		conn.Close()
+		return nil
+	default:
+	}
+	c.conn = conn
+	c.mu.Unlock()
+	defer conn.Close()
+
+	var writeMu sync.Mutex
+	write := func(line string) error {
+		writeMu.Lock()
+		defer writeMu.Unlock()
+		conn.SetWriteDeadline(time.Now().Add(ircTimeout))
+		_, err := fmt.Fprintf(conn, "%s\r\n", line)
+		return err
+	}
+
+	if c.Password != "" {
+		write("PASS " + c.Password)
+	}
+	nick := c.Nick
+	write("NICK " + nick)
+	write("USER " + c.Nick + " 0 * :PAARAbot")
+
+	// The reader handles the server messages, and closes joined once in the
+	// channel, and lost when the connection is.
+	joined := make(chan struct{})
+	lost := make(chan error, 1)
+	go func() {
+		reader := bufio.NewReader(conn)
+		pinged := false
+		partial := ""
+		for {
+			conn.SetReadDeadline(time.Now().Add(ircPingInterval))
+			line, err := reader.ReadString('\n')
+			if ne, ok := err.(net.Error); ok && ne.Timeout() && !pinged {
+				// Idle connection, make sure the server is still there
+				partial += line
+				pinged = true
+				write("PING :paarabot")
+				continue
+			}
+			if err != nil {
+				lost <- err
+				return
+			}
+			line, partial, pinged = partial+line, "", false
+			prefix, command, params := parseIRC(strings.TrimRight(line, "\r\n"))
+			switch command {
+			case "PING":
+				write("PONG :" + strings.Join(params, " "))
+			case "001":
+				write("JOIN " + c.Channel)
+			case "433":
+				// Nickname in use
+				nick += "_"
+				write("NICK " + nick)
+			case "JOIN":
+				if len(params) > 0 && strings.EqualFold(params[0], c.Channel) && strings.EqualFold(nickOf(prefix), nick) {
+					select {
+					case <-joined:
+					default:
+						close(joined)
+					}
+				}
+			case "KICK":
+				if len(params) > 1 && strings.EqualFold(params[1], nick) {
+					write("JOIN " + c.Channel)
+				}
+			case "PRIVMSG":
+				if len(params) == 2 {
+					c.command(nick, nickOf(prefix), params[0], params[1])
+				}
+			}
+		}
+	}()
+
+	select {
+	case <-joined:
+	case err := <-lost:
+		return err
+	case <-c.done:
+		return nil
+	case <-time.After(ircTimeout):
+		return fmt.Errorf("timeout joining %s", c.Channel)
+	}
+
+	// The writer sends the queued lines, one every FloodDelay, the posts
+	// before the answers.
+	flood := c.FloodDelay
+	if flood <= 0 {
+		flood = 2 * time.Second
+	}
+	for {
+		var line string
+		select {
+		case line = <-c.queue:
+		default:
+			select {
+			case err := <-lost:
+				return err
+			case <-c.done:
+				return nil
+			case line = <-c.queue:
+			case line = <-c.replies:
+			}
+		}
+		if err := write(line); err != nil {
+			return err
+		}
+		select {
+		case err := <-lost:
+			return err
+		case <-c.done:
+			return nil
+		case <-time.After(flood):
+		}
+	}
+}
+
+// command answers "!spots CALL", sent to the channel or to the bot, in
+// the background. The commands sent during the cooldown of the nick, or
+// while another one is looked up, are ignored.
+func (c *IRC) command(nick, from, target, text string) {
+	fields := strings.Fields(text)
+	if c.Spots == nil || len(fields) != 2 || !strings.EqualFold(fields[0], "!spots") {
+		return
+	}
+	// A command ignored while another lookup runs doesn't start the
+	// user's cooldown
+	select {
+	case c.lookup <- struct{}{}:
+	default:
+		return
+	}
+	if !c.allowCommand(from, time.Now()) {
+		<-c.lookup
+		return
+	}
+	// Private messages are answered in private
+	to := target
+	if strings.EqualFold(target, nick) {
+		to = from
+	}
+	go func() {
+		defer func() { <-c.lookup }()
+		for _, line := range c.lines(c.Spots(fields[1])) {
+			if err := enqueue(c.replies, to, line); err != nil {
+				fmt.Println("Error answering on IRC:", err)
+				return
+			}
+		}
+	}()
+}
+
+// allowCommand reports whether the nick may send a command at now, and
+// starts its cooldown if so.
+func (c *IRC) allowCommand(from string, now time.Time) bool {
+	cooldown := c.CommandCooldown
+	if cooldown <= 0 {
+		cooldown = 30 * time.Second
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	from = strings.ToLower(from)
+	if last, ok := c.commands[from]; ok && now.Sub(last) < cooldown {
+		return false
+	}
+	for nick, last := range c.commands {
+		if now.Sub(last) >= cooldown {
+			delete(c.commands, nick)
+		}
+	}
+	c.commands[from] = now
+	return true
+}
+
+// parseIRC splits a message of the server into its prefix, command and
+// parameters, the last one possibly containing spaces.
+func parseIRC(line string) (prefix, command string, params []string) {
+	if strings.HasPrefix(line, "@") {
+		// Message tags
+		_, line, _ = strings.Cut(line, " ")
+	}
+	if strings.HasPrefix(line, ":") {
+		prefix, line, _ = strings.Cut(line[1:], " ")
+	}
+	command, line, _ = strings.Cut(line, " ")
+	for line != "" {
+		if strings.HasPrefix(line, ":") {
+			params = append(params, line[1:])
+			break
+		}
+		var param string
+		param, line, _ = strings.Cut(line, " ")
+		if param != "" {
+			params = append(params, param)
+		}
+	}
+	return prefix, strings.ToUpper(command), params
+}
+
+// nickOf returns the nickname of a prefix, e.g. alice of alice!a@host.
+func nickOf(prefix string) string {
+	nick, _, _ := strings.Cut(prefix, "!")
+	return nick
+}
@@ -0,0 +1,199 @@
+package notify
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeIRC is a local IRC server accepting the connections of the client.
+type fakeIRC struct {
+	t        *testing.T
+	listener net.Listener
+}
+
+// ircConn is a connection accepted by the fake server.
+type ircConn struct {
+	t      *testing.T
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+func newFakeIRC(t *testing.T) *fakeIRC {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	return &fakeIRC{t: t, listener: listener}
+}
+
+// accept waits for the client, and welcomes it in the channel.
+func (f *fakeIRC) accept(nick, channel string) *ircConn {
+	f.listener.(*net.TCPListener).SetDeadline(time.Now().Add(5 * time.Second))
+	conn, err := f.listener.Accept()
+	if err != nil {
+		f.t.Fatal(err)
+	}
+	f.t.Cleanup(func() { conn.Close() })
+	c := &ircConn{t: f.t, conn: conn, reader: bufio.NewReader(conn)}
+	c.expect("NICK " + nick)
+	c.expect("USER " + nick + " 0 * :PAARAbot")
+	c.send(":irc.test 001 " + nick + " :Welcome")
+	c.expect("JOIN " + channel)
+	c.send(":" + nick + "!bot@host JOIN " + channel)
+	return c
+}
+
+func (c *ircConn) send(line string) {
+	fmt.Fprintf(c.conn, "%s\r\n", line)
+}
+
+// read returns the next line sent by the client.
+func (c *ircConn) read() string {
+	c.t.Helper()
+	c.conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	line, err := c.reader.ReadString('\n')
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	return strings.TrimRight(line, "\r\n")
+}
+
+func (c *ircConn) expect(want string) {
+	c.t.Helper()
+	if got := c.read(); got != want {
+		c.t.Fatalf("Expected %q, got %q", want, got)
+	}
+}
+
+func TestIRC(t *testing.T) {
+	server := newFakeIRC(t)
+	client := &IRC{
+		Server:         server.listener.Addr().String(),
+		Nick:           "paarabot",
+		Channel:        "#paara",
+		FloodDelay:     100 * time.Millisecond,
+		ReconnectDelay: 10 * time.Millisecond,
+		// The replies are throttled per nick
+		CommandCooldown: time.Hour,
+		Spots: func(callsign string) string {
+			return "Most recent 10 spots for **" + callsign + "**:\n- **POTA** [10/19 16:00] US-4491 14062 CW\n"
+		},
+	}
+	// Messages posted before connecting are kept
+	if err := client.Notify(testMessage); err != nil {
+		t.Fatal(err)
+	}
+	client.Start()
+	defer client.Close()
+
+	conn := server.accept("paarabot", "#paara")
+	conn.expect("PRIVMSG #paara :\x0303\x02[POTA]\x0f \x02KN6YUH\x02 at US-4491 <Superior> & \x1eUS-4816\x1e")
+
+	// Control characters of the spot comments can't inject commands
+	client.Notify(Message{Program: "SOTA", Text: "W6/CT-001 [QRT\rQUIT :bye\x00\x03]"})
+	conn.expect("PRIVMSG #paara :\x0307\x02[SOTA]\x0f W6/CT-001 [QRT QUIT :bye  ]")
+
+	conn.send("PING :irc.test")
+	conn.expect("PONG :irc.test")
+
+	// Commands are answered where they're sent, with flood control
+	conn.send(":alice!a@host PRIVMSG #paara :!spots kn6yuh")
+	start := time.Now()
+	conn.expect("PRIVMSG #paara :Most recent 10 spots for \x02kn6yuh\x02:")
+	conn.expect("PRIVMSG #paara :- \x02POTA\x02 [10/19 16:00] US-4491 14062 CW")
+	if elapsed := time.Since(start); elapsed < client.FloodDelay {
+		t.Errorf("Lines sent %v apart, expected at least %v", elapsed, client.FloodDelay)
+	}
+	conn.send(":alice!a@host PRIVMSG #paara :!spots AK6EU")
+	conn.send(":bob!b@host PRIVMSG paarabot :!spots W6/CT-001")
+	conn.expect("PRIVMSG bob :Most recent 10 spots for \x02W6/CT-001\x02:")
+	conn.read()
+	// Other messages are ignored
+	conn.send(":alice!a@host PRIVMSG #paara :hello")
+
+	// The client reconnects when the connection is lost
+	conn.conn.Close()
+	conn = server.accept("paarabot", "#paara")
+	if err := client.Notify(Message{Title: "Digest", Text: "**Weekly digest**\n\n3 activations"}); err != nil {
+		t.Fatal(err)
+	}
+	conn.expect("PRIVMSG #paara :\x02Weekly digest\x02")
+	conn.expect("PRIVMSG #paara :3 activations")
+}
+
+func TestIRCNickInUse(t *testing.T) {
+	server := newFakeIRC(t)
+	client := &IRC{Server: server.listener.Addr().String(), Nick: "paarabot", Channel: "#paara", Plain: true}
+	client.Start()
+	defer client.Close()
+
+	server.listener.(*net.TCPListener).SetDeadline(time.Now().Add(5 * time.Second))
+	nc, err := server.listener.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer nc.Close()
+	conn := &ircConn{t: t, conn: nc, reader: bufio.NewReader(nc)}
+	conn.expect("NICK paarabot")
+	conn.read()
+	conn.send(":irc.test 433 * paarabot :Nickname is already in use")
+	conn.expect("NICK paarabot_")
+	conn.send(":irc.test 001 paarabot_ :Welcome")
+	conn.expect("JOIN #paara")
+	conn.send(":paarabot_!bot@host JOIN :#paara")
+
+	client.Notify(testMessage)
+	conn.expect("PRIVMSG #paara :[POTA] KN6YUH at US-4491 <Superior> & US-4816")
+}
+
+func TestIRCRepliesQueue(t *testing.T) {
+	client := &IRC{Nick: "paarabot", Channel: "#paara", Spots: func(callsign string) string {
+		return strings.Repeat("- **POTA** US-4491 14062 CW\n", 10)
+	}}
+	client.Notify(testMessage)
+
+	// Commands can't push out the posts, even from many nicks
+	for i := range 2 * ircRepliesSize {
+		client.command("paarabot", fmt.Sprintf("user%d", i), "#paara", "!spots KN6YUH")
+		client.lookup <- struct{}{}
+		<-client.lookup
+	}
+	if len(client.replies) != ircRepliesSize || len(client.queue) != 1 {
+		t.Errorf("Expected full replies and 1 post, got %d and %d", len(client.replies), len(client.queue))
+	}
+	if err := client.Notify(testMessage); err != nil || len(client.queue) != 2 {
+		t.Errorf("Post refused: %v", err)
+	}
+	// A nick repeating the command is ignored
+	if client.allowCommand("USER0", time.Now()) {
+		t.Error("Command allowed during the cooldown")
+	}
+	if !client.allowCommand("user0", time.Now().Add(time.Minute)) {
+		t.Error("Command refused after the cooldown")
+	}
+	// A command ignored while another lookup runs can be sent again
+	client.lookup <- struct{}{}
+	client.command("paarabot", "busy", "#paara", "!spots KN6YUH")
+	<-client.lookup
+	if !client.allowCommand("busy", time.Now()) {
+		t.Error("Command ignored during a lookup started the cooldown")
+	}
+}
+
+func TestParseIRC(t *testing.T) {
+	prefix, command, params := parseIRC("@time=2026-10-19T16:00:00Z :alice!a@host privmsg #paara :!spots  KN6YUH ")
+	if prefix != "alice!a@host" || command != "PRIVMSG" || len(params) != 2 || params[0] != "#paara" || params[1] != "!spots  KN6YUH " {
+		t.Errorf("parseIRC() = %q %q %q", prefix, command, params)
+	}
+	if nick := nickOf(prefix); nick != "alice" {
+		t.Errorf("nickOf() = %q", nick)
+	}
+	if got := truncateLine(strings.Repeat("é", 300)); len(got) != 400 || !strings.HasSuffix(got, "é") {
+		t.Errorf("truncateLine() = %d bytes", len(got))
+	}
+}
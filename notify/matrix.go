@@ -0,0 +1,37 @@
+package notify
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Matrix posts to a Matrix room, through the client-server API of the
+// homeserver, as the user of the access token.
+type Matrix struct {
+	// Homeserver is the URL of the homeserver, e.g. https://matrix.org.
+	Homeserver  string
+	RoomID      string
+	AccessToken string
+}
+
+func (m Matrix) Name() string { return "Matrix" }
+
+// txnCounter makes the transaction IDs unique within the process.
+var txnCounter atomic.Int64
+
+func (m Matrix) Notify(msg Message) error {
+	txn := fmt.Sprintf("paarabot-%d-%d", time.Now().UnixNano(), txnCounter.Add(1))
+	endpoint := fmt.Sprintf("%s/_matrix/client/v3/rooms/%s/send/m.room.message/%s",
+		strings.TrimRight(m.Homeserver, "/"), url.PathEscape(m.RoomID), txn)
+	body := map[string]string{
+		"msgtype":        "m.notice",
+		"body":           Plain(msg.Text),
+		"format":         "org.matrix.custom.html",
+		"formatted_body": HTML(msg.Text),
+	}
+	headers := map[string]string{"Authorization": "Bearer " + m.AccessToken}
+	return sendJSON("PUT", endpoint, headers, body, nil)
+}
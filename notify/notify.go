@@ -0,0 +1,109 @@
+// This package posts the messages of the bot to chat services other than
+// Discord: Slack, Matrix, Telegram, IRC, or any HTTP endpoint accepting JSON.
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Notifier posts messages to a chat service.
+type Notifier interface {
+	// Name identifies the notifier in the logs, e.g. "Slack".
+	Name() string
+	Notify(m Message) error
+}
+
+// Message is a message of the bot, e.g. a spot or a planned activation.
+type Message struct {
+	// Program is POTA or SOTA, or empty for messages such as digests.
+	Program string `json:"program,omitempty"`
+	// Title is a short summary, e.g. "KN6YUH at US-4491".
+	Title string `json:"title"`
+	// Text is the message, using the Discord markdown: **bold** and
+	// ~~strikethrough~~.
+	Text string `json:"text"`
+	// Tags label the message, starting with its program, e.g. POTA, 20m
+	// and CW.
+	Tags []string `json:"tags,omitempty"`
+	// Spot is set for the messages about a spot.
+	Spot *Spot `json:"spot,omitempty"`
+}
+
+// Spot holds the details of a spotted activation.
+type Spot struct {
+	Callsign   string   `json:"callsign"`
+	References []string `json:"references"`
+	Name       string   `json:"name,omitempty"`
+	Hz         int64    `json:"hz,omitempty"`
+	Band       string   `json:"band,omitempty"`
+	Mode       string   `json:"mode,omitempty"`
+	Comments   string   `json:"comments,omitempty"`
+	Spotter    string   `json:"spotter,omitempty"`
+	Time       string   `json:"time,omitempty"`
+}
+
+// httpClient posts the messages.
+var httpClient = &http.Client{Timeout: 30 * time.Second}
+
+var (
+	boldPattern   = regexp.MustCompile(`\*\*(.+?)\*\*`)
+	strikePattern = regexp.MustCompile(`~~(.+?)~~`)
+)
+
+// Plain removes the markdown from the text.
+func Plain(text string) string {
+	return strikePattern.ReplaceAllString(boldPattern.ReplaceAllString(text, "$1"), "$1")
+}
+
+// HTML converts the text to HTML.
+func HTML(text string) string {
+	return html(text, "<br>")
+}
+
+// html converts the text to HTML, joining the lines with the separator.
+func html(text, separator string) string {
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	for i, line := range lines {
+		line = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;").Replace(line)
+		line = boldPattern.ReplaceAllString(line, "<b>$1</b>")
+		lines[i] = strikePattern.ReplaceAllString(line, "<del>$1</del>")
+	}
+	return strings.Join(lines, separator)
+}
+
+// sendJSON sends the body as JSON, and decodes the response into result,
+// if not nil.
+func sendJSON(method, url string, headers map[string]string, body, result any) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(method, url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	if result == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(result)
+}
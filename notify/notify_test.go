@@ -0,0 +1,164 @@
+package notify
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testMessage = Message{
+	Program: "POTA",
+	Title:   "KN6YUH at US-4491",
+	Text:    "**KN6YUH** at US-4491 <Superior> & ~~US-4816~~",
+	Tags:    []string{"POTA", "20m", "CW"},
+	Spot:    &Spot{Callsign: "KN6YUH", References: []string{"US-4491"}, Hz: 14062000, Band: "20m", Mode: "CW"},
+}
+
+// request is a request received by the stand-in server.
+type request struct {
+	method, path, auth string
+	body               map[string]any
+}
+
+// standIn starts a server recording the requests, and answering with the
+// response.
+func standIn(t *testing.T, status int, response string) (*httptest.Server, *request) {
+	got := &request{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got.method, got.path, got.auth = r.Method, r.URL.Path, r.Header.Get("Authorization")
+		data, _ := io.ReadAll(r.Body)
+		if err := json.Unmarshal(data, &got.body); err != nil {
+			t.Errorf("Invalid JSON body %q: %v", data, err)
+		}
+		w.WriteHeader(status)
+		io.WriteString(w, response)
+	}))
+	t.Cleanup(server.Close)
+	return server, got
+}
+
+func TestMarkup(t *testing.T) {
+	if got := Plain(testMessage.Text); got != "KN6YUH at US-4491 <Superior> & US-4816" {
+		t.Errorf("Plain() = %q", got)
+	}
+	if got := HTML(testMessage.Text + "\nQRT\n"); got != "<b>KN6YUH</b> at US-4491 &lt;Superior&gt; &amp; <del>US-4816</del><br>QRT" {
+		t.Errorf("HTML() = %q", got)
+	}
+	if got := mrkdwn(testMessage.Text); got != "*KN6YUH* at US-4491 &lt;Superior&gt; &amp; ~US-4816~" {
+		t.Errorf("mrkdwn() = %q", got)
+	}
+}
+
+func TestSlack(t *testing.T) {
+	server, got := standIn(t, http.StatusOK, "ok")
+	if err := (Slack{WebhookURL: server.URL + "/services/T0/B0/x"}).Notify(testMessage); err != nil {
+		t.Fatal(err)
+	}
+	if got.method != "POST" || got.path != "/services/T0/B0/x" || got.body["text"] != mrkdwn(testMessage.Text) {
+		t.Errorf("Unexpected request: %+v", got)
+	}
+
+	server, _ = standIn(t, http.StatusNotFound, "no_service")
+	if err := (Slack{WebhookURL: server.URL}).Notify(testMessage); err == nil || !strings.Contains(err.Error(), "no_service") {
+		t.Errorf("Notify() = %v, want the Slack error", err)
+	}
+}
+
+func TestMatrix(t *testing.T) {
+	server, got := standIn(t, http.StatusOK, `{"event_id":"$1"}`)
+	m := Matrix{Homeserver: server.URL + "/", RoomID: "!room:example.org", AccessToken: "secret"}
+	if err := m.Notify(testMessage); err != nil {
+		t.Fatal(err)
+	}
+	if got.method != "PUT" || !strings.HasPrefix(got.path, "/_matrix/client/v3/rooms/!room:example.org/send/m.room.message/paarabot-") {
+		t.Errorf("Unexpected request: %s %s", got.method, got.path)
+	}
+	if got.auth != "Bearer secret" || got.body["body"] != Plain(testMessage.Text) || got.body["formatted_body"] != HTML(testMessage.Text) {
+		t.Errorf("Unexpected request: %+v", got)
+	}
+
+	// Each message is a new transaction
+	first := got.path
+	m.Notify(testMessage)
+	if got.path == first {
+		t.Error("Transaction IDs should be unique")
+	}
+}
+
+func TestTelegram(t *testing.T) {
+	server, got := standIn(t, http.StatusOK, `{"ok":true,"result":{}}`)
+	tg := Telegram{Token: "123:secret", ChatID: "-1001", APIURL: server.URL}
+	if err := tg.Notify(testMessage); err != nil {
+		t.Fatal(err)
+	}
+	if got.path != "/bot123:secret/sendMessage" || got.body["chat_id"] != "-1001" || got.body["parse_mode"] != "HTML" || got.body["text"] != html(testMessage.Text, "\n") {
+		t.Errorf("Unexpected request: %+v", got)
+	}
+
+	server, _ = standIn(t, http.StatusBadRequest, `{"ok":false,"description":"chat not found"}`)
+	tg.APIURL = server.URL
+	err := tg.Notify(testMessage)
+	if err == nil || !strings.Contains(err.Error(), "chat not found") || strings.Contains(err.Error(), "secret") {
+		t.Errorf("Notify() = %v, want the Telegram error without the token", err)
+	}
+}
+
+func TestWebhook(t *testing.T) {
+	server, got := standIn(t, http.StatusNoContent, "")
+	if err := (Webhook{URL: server.URL + "/hook"}).Notify(testMessage); err != nil {
+		t.Fatal(err)
+	}
+	spot, _ := got.body["spot"].(map[string]any)
+	if got.path != "/hook" || got.body["program"] != "POTA" || got.body["title"] != testMessage.Title || spot["callsign"] != "KN6YUH" || spot["hz"] != 14062000.0 {
+		t.Errorf("Unexpected request: %+v", got)
+	}
+}
+
+// blocked is a notifier waiting for its release before each message.
+type blocked struct {
+	release  chan struct{}
+	received chan Message
+}
+
+func (b blocked) Name() string { return "Blocked" }
+
+func (b blocked) Notify(m Message) error {
+	<-b.release
+	b.received <- m
+	return nil
+}
+
+func TestQueue(t *testing.T) {
+	b := blocked{release: make(chan struct{}), received: make(chan Message, queueSize+1)}
+	q := NewQueue(b)
+	if q.Name() != "Blocked" {
+		t.Errorf("Name() = %q", q.Name())
+	}
+
+	// The first message is being delivered, the next ones are queued
+	for i := 0; i <= queueSize; i++ {
+		if err := q.Notify(Message{Title: fmt.Sprint(i)}); err != nil {
+			t.Fatalf("Notify(%d) failed: %v", i, err)
+		}
+		if i == 0 {
+			for len(q.messages) > 0 {
+				time.Sleep(time.Millisecond)
+			}
+		}
+	}
+	if err := q.Notify(testMessage); err == nil {
+		t.Error("Notify should fail when the queue is full")
+	}
+
+	close(b.release)
+	for i := 0; i <= queueSize; i++ {
+		if m := <-b.received; m.Title != fmt.Sprint(i) {
+			t.Fatalf("Received %q, want %d", m.Title, i)
+		}
+	}
+}
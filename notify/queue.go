@@ -0,0 +1,42 @@
+package notify
+
+import "fmt"
+
+// queueSize is how many messages a Queue holds before dropping the new ones.
+const queueSize = 100
+
+// Queue delivers the messages to a notifier from its own goroutine, as the
+// IRC notifier does with its lines, so that a slow or unreachable chat
+// service doesn't hold up the bot. The messages are dropped when the queue
+// is full.
+type Queue struct {
+	Notifier
+	messages chan Message
+}
+
+// NewQueue starts delivering the queued messages to the notifier.
+func NewQueue(n Notifier) *Queue {
+	q := &Queue{Notifier: n, messages: make(chan Message, queueSize)}
+	go q.run()
+	return q
+}
+
+// Notify queues the message, failing when the queue is full.
+func (q *Queue) Notify(m Message) error {
+	select {
+	case q.messages <- m:
+		return nil
+	default:
+		return fmt.Errorf("%s queue full, dropping %q", q.Name(), m.Title)
+	}
+}
+
+// run delivers the queued messages, logging the errors as there is no one
+// to return them to.
+func (q *Queue) run() {
+	for m := range q.messages {
+		if err := q.Notifier.Notify(m); err != nil {
+			fmt.Printf("Error notifying %s: %v\n", q.Name(), err)
+		}
+	}
+}
@@ -0,0 +1,22 @@
+package notify
+
+import "strings"
+
+// Slack posts to a Slack incoming webhook.
+type Slack struct {
+	WebhookURL string
+}
+
+func (s Slack) Name() string { return "Slack" }
+
+// mrkdwn converts the text to Slack's markdown, escaping its control
+// characters.
+func mrkdwn(text string) string {
+	text = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(text)
+	text = boldPattern.ReplaceAllString(text, "*$1*")
+	return strikePattern.ReplaceAllString(text, "~$1~")
+}
+
+func (s Slack) Notify(m Message) error {
+	return sendJSON("POST", s.WebhookURL, nil, map[string]string{"text": mrkdwn(m.Text)}, nil)
+}
@@ -0,0 +1,43 @@
+package notify
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Telegram posts to a Telegram chat, through the Bot API.
+type Telegram struct {
+	Token  string
+	ChatID string
+	// APIURL defaults to https://api.telegram.org.
+	APIURL string
+}
+
+func (t Telegram) Name() string { return "Telegram" }
+
+// Notify posts the message. Telegram's HTML doesn't support <br>, so the
+// lines are kept as they are.
+func (t Telegram) Notify(m Message) error {
+	api := t.APIURL
+	if api == "" {
+		api = "https://api.telegram.org"
+	}
+	body := map[string]any{
+		"chat_id":                  t.ChatID,
+		"text":                     html(m.Text, "\n"),
+		"parse_mode":               "HTML",
+		"disable_web_page_preview": true,
+	}
+	var result struct {
+		OK          bool   `json:"ok"`
+		Description string `json:"description"`
+	}
+	if err := sendJSON("POST", strings.TrimRight(api, "/")+"/bot"+t.Token+"/sendMessage", nil, body, &result); err != nil {
+		// Don't log the token
+		return fmt.Errorf("%s", strings.ReplaceAll(err.Error(), t.Token, "<token>"))
+	}
+	if !result.OK {
+		return fmt.Errorf("telegram error: %s", result.Description)
+	}
+	return nil
+}
@@ -0,0 +1,16 @@
+package notify
+
+// Webhook posts the messages as JSON to any HTTP endpoint, e.g. to feed
+// another application. The body is the Message, e.g.:
+//
+//	{"program":"POTA","title":"KN6YUH at US-4491","text":"...","tags":["POTA","20m","CW"],
+//	 "spot":{"callsign":"KN6YUH","references":["US-4491"],"hz":14062000,"band":"20m","mode":"CW"}}
+type Webhook struct {
+	URL string
+}
+
+func (w Webhook) Name() string { return "Webhook" }
+
+func (w Webhook) Notify(m Message) error {
+	return sendJSON("POST", w.URL, nil, m, nil)
+}
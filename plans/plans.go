@@ -0,0 +1,235 @@
+// This package keeps track of the planned activations of the tracked
+// callsigns, announced with SOTA alerts or POTA schedules, along with the
+// Discord messages announcing them. The plans are saved to a JSON file, so
+// that they aren't announced again when the bot restarts.
+package plans
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/PAARA-org/PAARAbot/hams"
+)
+
+// keep is how long past plans are kept after their end.
+const keep = 24 * time.Hour
+
+// Message is a Discord message about a plan.
+type Message struct {
+	ChannelID string `json:"channelID"`
+	MessageID string `json:"messageID"`
+}
+
+// Plan is a planned activation.
+type Plan struct {
+	// ID is stable across polls, e.g. SOTA-12345 for a SOTA alert.
+	ID          string    `json:"id"`
+	Program     string    `json:"program"`
+	Callsign    string    `json:"callsign"`
+	Reference   string    `json:"reference"`
+	Name        string    `json:"name"`
+	Start       time.Time `json:"start"`
+	End         time.Time `json:"end"`
+	Frequencies string    `json:"frequencies"`
+	Comments    string    `json:"comments"`
+
+	// Announcements are the messages announcing the plan.
+	Announcements []Message `json:"announcements,omitempty"`
+	// EventID is the Discord scheduled event of the plan.
+	EventID  string `json:"eventID,omitempty"`
+	Reminded bool   `json:"reminded,omitempty"`
+	// Live is set once the activator was spotted at the reference.
+	Live bool `json:"live,omitempty"`
+}
+
+// Same reports whether the plans have the same details, ignoring the state
+// of their announcements.
+func (p Plan) Same(o Plan) bool {
+	return p.ID == o.ID && p.Program == o.Program && p.Callsign == o.Callsign && p.Reference == o.Reference &&
+		p.Name == o.Name && p.Start.Equal(o.Start) && p.End.Equal(o.End) && p.Frequencies == o.Frequencies && p.Comments == o.Comments
+}
+
+// Changes are the differences between the stored plans of a program and its
+// current plans.
+type Changes struct {
+	Added   []Plan
+	Changed []Plan
+	// Removed lists the upcoming plans that were withdrawn.
+	Removed []Plan
+}
+
+// Store holds the plans. It is safe for concurrent use.
+type Store struct {
+	mu    sync.Mutex
+	path  string
+	plans map[string]*Plan
+}
+
+// Load reads the plans stored in the JSON file at path. A missing file
+// results in an empty store. If path is empty, the plans are only kept in
+// memory.
+func Load(path string) (*Store, error) {
+	s := &Store{path: path, plans: make(map[string]*Plan)}
+	if path == "" {
+		return s, nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read plans %s: %w", path, err)
+	}
+	if err := json.Unmarshal(data, &s.plans); err != nil {
+		return nil, fmt.Errorf("failed to parse plans %s: %w", path, err)
+	}
+	return s, nil
+}
+
+// save writes the plans to disk. It must be called with the lock held.
+func (s *Store) save() error {
+	if s.path == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(s.plans, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write plans: %w", err)
+	}
+	return os.Rename(tmp, s.path)
+}
+
+// Update replaces the plans of the program with the current ones, as
+// fetched from its feed, and returns the changes. Past plans are forgotten.
+func (s *Store) Update(program string, current []Plan, now time.Time) (Changes, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var changes Changes
+	ids := make(map[string]bool)
+	for _, p := range current {
+		ids[p.ID] = true
+		old, ok := s.plans[p.ID]
+		switch {
+		case !ok:
+			plan := p
+			s.plans[p.ID] = &plan
+			changes.Added = append(changes.Added, plan)
+		case !old.Same(p):
+			// Keep the state of the announcements
+			p.Announcements, p.EventID, p.Live = old.Announcements, old.EventID, old.Live
+			p.Reminded = old.Reminded && old.Start.Equal(p.Start)
+			*old = p
+			changes.Changed = append(changes.Changed, p)
+		}
+	}
+	for id, p := range s.plans {
+		if p.Program != program || ids[id] {
+			continue
+		}
+		// Plans leave the feed once they're over, they're only withdrawn
+		// when they were still to come.
+		if p.Start.After(now) {
+			changes.Removed = append(changes.Removed, *p)
+		}
+		if p.Start.After(now) || p.End.Add(keep).Before(now) {
+			delete(s.plans, id)
+		}
+	}
+	byStart := func(list []Plan) {
+		sort.Slice(list, func(i, j int) bool { return list[i].Start.Before(list[j].Start) })
+	}
+	byStart(changes.Added)
+	byStart(changes.Changed)
+	byStart(changes.Removed)
+	return changes, s.save()
+}
+
+// update applies f to the plan, and saves the plans.
+func (s *Store) update(id string, f func(p *Plan)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p, ok := s.plans[id]
+	if !ok {
+		return nil
+	}
+	f(p)
+	return s.save()
+}
+
+// SetAnnouncements records the messages announcing the plan.
+func (s *Store) SetAnnouncements(id string, messages []Message) error {
+	return s.update(id, func(p *Plan) { p.Announcements = messages })
+}
+
+// SetEvent records the Discord scheduled event of the plan.
+func (s *Store) SetEvent(id, eventID string) error {
+	return s.update(id, func(p *Plan) { p.EventID = eventID })
+}
+
+// MarkReminded records that the reminder of the plan was posted.
+func (s *Store) MarkReminded(id string) error {
+	return s.update(id, func(p *Plan) { p.Reminded = true })
+}
+
+// MarkLive records that the activator was spotted at the planned reference.
+func (s *Store) MarkLive(id string) error {
+	return s.update(id, func(p *Plan) { p.Live = true })
+}
+
+// Due returns the plans starting within lead of now, whose reminder wasn't
+// posted yet.
+func (s *Store) Due(now time.Time, lead time.Duration) []Plan {
+	return s.list(func(p *Plan) bool {
+		return !p.Reminded && !p.Live && !p.Start.After(now.Add(lead)) && p.Start.After(now.Add(-lead))
+	})
+}
+
+// Match returns the plan of the callsign at one of the references, which
+// hasn't been spotted live yet and is around now. Portable suffixes of the
+// callsigns are ignored.
+func (s *Store) Match(program, callsign string, references []string, now time.Time) (Plan, bool) {
+	list := s.list(func(p *Plan) bool {
+		return !p.Live && p.Program == program && hams.BaseCall(p.Callsign) == hams.BaseCall(callsign) &&
+			slices.ContainsFunc(references, func(ref string) bool { return strings.EqualFold(ref, p.Reference) }) &&
+			now.After(p.Start.Add(-keep)) && now.Before(p.End.Add(keep))
+	})
+	if len(list) == 0 {
+		return Plan{}, false
+	}
+	return list[0], true
+}
+
+// Upcoming returns the plans which aren't over, by start time.
+func (s *Store) Upcoming(now time.Time) []Plan {
+	return s.list(func(p *Plan) bool { return p.End.After(now) })
+}
+
+// list returns the plans selected by the filter, by start time.
+func (s *Store) list(filter func(p *Plan) bool) []Plan {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var result []Plan
+	for _, p := range s.plans {
+		if filter(p) {
+			result = append(result, *p)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].Start.Equal(result[j].Start) {
+			return result[i].Start.Before(result[j].Start)
+		}
+		return result[i].ID < result[j].ID
+	})
+	return result
+}
@@ -0,0 +1,86 @@
+package plans
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "plans.json")
+	s, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	umunhum := Plan{ID: "SOTA-1", Program: "SOTA", Callsign: "KN6YUH", Reference: "W6/CT-001", Start: now.Add(4 * time.Hour), End: now.Add(6 * time.Hour), Frequencies: "20m CW"}
+	prieta := Plan{ID: "SOTA-2", Program: "SOTA", Callsign: "AK6EU", Reference: "W6/CT-002", Start: now.Add(time.Hour), End: now.Add(3 * time.Hour)}
+
+	changes, err := s.Update("SOTA", []Plan{umunhum, prieta}, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes.Added) != 2 || changes.Added[0].ID != "SOTA-2" || len(changes.Changed) != 0 || len(changes.Removed) != 0 {
+		t.Fatalf("Unexpected changes: %+v", changes)
+	}
+	s.SetAnnouncements("SOTA-1", []Message{{ChannelID: "1", MessageID: "10"}})
+	s.SetEvent("SOTA-1", "20")
+
+	// The plans are remembered across restarts
+	s, err = Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	changes, _ = s.Update("SOTA", []Plan{umunhum, prieta}, now)
+	if len(changes.Added) != 0 || len(changes.Changed) != 0 {
+		t.Errorf("Unchanged plans reported: %+v", changes)
+	}
+
+	// Changed plans keep their announcements, withdrawn ones are removed
+	umunhum.Frequencies = "20m CW, 40m CW"
+	changes, _ = s.Update("SOTA", []Plan{umunhum}, now)
+	if len(changes.Changed) != 1 || len(changes.Changed[0].Announcements) != 1 || changes.Changed[0].EventID != "20" {
+		t.Errorf("Unexpected changed plans: %+v", changes.Changed)
+	}
+	if len(changes.Removed) != 1 || changes.Removed[0].ID != "SOTA-2" {
+		t.Errorf("Unexpected removed plans: %+v", changes.Removed)
+	}
+	// Plans of the other programs are left alone
+	if changes, _ := s.Update("POTA", nil, now); len(changes.Removed) != 0 {
+		t.Errorf("Unexpected removed plans: %+v", changes.Removed)
+	}
+
+	if due := s.Due(now, 30*time.Minute); len(due) != 0 {
+		t.Errorf("Nothing is due yet: %+v", due)
+	}
+	later := now.Add(3*time.Hour + 45*time.Minute)
+	if due := s.Due(later, 30*time.Minute); len(due) != 1 || due[0].ID != "SOTA-1" {
+		t.Errorf("Due() = %+v", due)
+	}
+	s.MarkReminded("SOTA-1")
+	if due := s.Due(later, 30*time.Minute); len(due) != 0 {
+		t.Errorf("Reminded plans aren't due: %+v", due)
+	}
+
+	if _, ok := s.Match("SOTA", "KN6YUH", []string{"W6/CT-002"}, later); ok {
+		t.Error("Match() found a plan at another summit")
+	}
+	p, ok := s.Match("SOTA", "kn6yuh/p", []string{"w6/ct-001"}, later)
+	if !ok || p.ID != "SOTA-1" {
+		t.Fatalf("Match() = %+v, %v", p, ok)
+	}
+	s.MarkLive(p.ID)
+	if _, ok := s.Match("SOTA", "KN6YUH", []string{"W6/CT-001"}, later); ok {
+		t.Error("Match() found a plan already live")
+	}
+
+	if upcoming := s.Upcoming(now.Add(7 * time.Hour)); len(upcoming) != 0 {
+		t.Errorf("Upcoming() = %+v", upcoming)
+	}
+	// Past plans are forgotten once they leave the feed
+	s.Update("SOTA", nil, now.Add(48*time.Hour))
+	if upcoming := s.Upcoming(now); len(upcoming) != 0 {
+		t.Errorf("Past plans are kept: %+v", upcoming)
+	}
+}
@@ -0,0 +1,122 @@
+package pota
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Park is a park of the POTA parks list.
+type Park struct {
+	Reference    string
+	Name         string
+	Active       bool
+	EntityID     int
+	LocationDesc string
+	Latitude     float64
+	Longitude    float64
+	Grid         string
+	// Activations is only known when the list has an "activations" column.
+	Activations int
+}
+
+// parks stores the parks list, by reference.
+var (
+	parks   = make(map[string]Park)
+	parksMu sync.RWMutex
+)
+
+// SetParks replaces the parks list in a thread-safe manner.
+func SetParks(list map[string]Park) {
+	parksMu.Lock()
+	defer parksMu.Unlock()
+	parks = list
+}
+
+// ParksCount returns the number of parks in the list.
+func ParksCount() int {
+	parksMu.RLock()
+	defer parksMu.RUnlock()
+	return len(parks)
+}
+
+// LookupPark returns the park with the given reference, e.g. US-4491.
+func LookupPark(reference string) (Park, bool) {
+	parksMu.RLock()
+	defer parksMu.RUnlock()
+	p, ok := parks[strings.ToUpper(strings.TrimSpace(reference))]
+	return p, ok
+}
+
+// ParseParksFile parses a local copy of the parks list, such as
+// https://pota.app/all_parks_ext.csv
+func ParseParksFile(filePath string) (map[string]Park, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file %s: %w", filePath, err)
+	}
+	defer file.Close()
+	return ParseParks(file)
+}
+
+// ParseParks parses the parks list read from r. Columns are found by their
+// name in the header, which must include at least "reference" and "name":
+//
+//	"reference","name","active","entityId","locationDesc","latitude","longitude","grid"
+func ParseParks(r io.Reader) (map[string]Park, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read header: %w", err)
+	}
+	columns := make(map[string]int)
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	if _, ok := columns["reference"]; !ok {
+		return nil, fmt.Errorf("unexpected header, expected reference and name columns")
+	}
+	if _, ok := columns["name"]; !ok {
+		return nil, fmt.Errorf("unexpected header, expected reference and name columns")
+	}
+
+	list := make(map[string]Park)
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		field := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		p := Park{
+			Reference:    strings.ToUpper(field("reference")),
+			Name:         field("name"),
+			Active:       field("active") != "0",
+			LocationDesc: field("locationdesc"),
+			Grid:         field("grid"),
+		}
+		if p.Reference == "" {
+			continue
+		}
+		p.EntityID, _ = strconv.Atoi(field("entityid"))
+		p.Latitude, _ = strconv.ParseFloat(field("latitude"), 64)
+		p.Longitude, _ = strconv.ParseFloat(field("longitude"), 64)
+		p.Activations, _ = strconv.Atoi(field("activations"))
+		list[p.Reference] = p
+	}
+	return list, nil
+}
@@ -0,0 +1,31 @@
+package pota
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseParks(t *testing.T) {
+	csv := `"reference","name","active","entityId","locationDesc","latitude","longitude","grid"
+"US-4491","Superior National Forest","1","291","US-MN","47.7","-91.5","EN47"
+"us-0001","Acadia National Park","0","291","US-ME","44.31","-68.2034","FN54"
+`
+	list, err := ParseParks(strings.NewReader(csv))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 2 {
+		t.Fatalf("Expected 2 parks, got %d", len(list))
+	}
+	p := list["US-4491"]
+	if p.Name != "Superior National Forest" || !p.Active || p.LocationDesc != "US-MN" || p.Latitude != 47.7 || p.Grid != "EN47" {
+		t.Errorf("Unexpected park: %+v", p)
+	}
+	if p := list["US-0001"]; p.Active {
+		t.Errorf("Park should be inactive: %+v", p)
+	}
+
+	if _, err := ParseParks(strings.NewReader("<html>Maintenance</html>\n")); err == nil {
+		t.Error("ParseParks should fail without a header")
+	}
+}
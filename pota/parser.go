@@ -5,9 +5,16 @@ import (
 	"encoding/json"
 	"io"
 	"net/http"
+	"regexp"
+	"slices"
+	"strings"
 )
 
-type PotaSpot []struct {
+// referencePattern matches POTA park references, e.g. US-4491 or VE-0123.
+var referencePattern = regexp.MustCompile(`\b[A-Z0-9]{1,4}-[0-9]{4,5}\b`)
+
+// Spot is a single POTA spot as returned by the API.
+type Spot struct {
 	SpotID       int    `json:"spotId"`
 	SpotTime     string `json:"spotTime"`
 	Activator    string `json:"activator"`
@@ -21,6 +28,8 @@ type PotaSpot []struct {
 	LocationDesc string `json:"locationDesc"`
 }
 
+type PotaSpot []Spot
+
 func ListSpots() (result PotaSpot, err error) {
 
 	resp, err := http.Get("https://api.pota.app/spot/")
@@ -38,3 +47,29 @@ func ListSpots() (result PotaSpot, err error) {
 	}
 	return
 }
+
+// ParseReferences returns the unique POTA references found in the text, in
+// order of appearance. Several references can be separated by anything,
+// e.g. "US-4491/US-4816" or "2fer US-4491 US-4816".
+func ParseReferences(text string) []string {
+	var refs []string
+	for _, ref := range referencePattern.FindAllString(strings.ToUpper(text), -1) {
+		if !slices.Contains(refs, ref) {
+			refs = append(refs, ref)
+		}
+	}
+	return refs
+}
+
+// References returns all the references of a (possibly multi-park, or
+// n-fer) activation: the spot's reference first, followed by the other
+// references mentioned in the comments.
+func (s Spot) References() []string {
+	refs := []string{s.Reference}
+	for _, ref := range ParseReferences(s.Comments) {
+		if !slices.Contains(refs, ref) {
+			refs = append(refs, ref)
+		}
+	}
+	return refs
+}
@@ -0,0 +1,31 @@
+package pota
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseReferences(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"US-4491", []string{"US-4491"}},
+		{"US-4491/US-4816", []string{"US-4491", "US-4816"}},
+		{"2fer with us-4816 and US-4491, then US-4816 again", []string{"US-4816", "US-4491"}},
+		{"W6/CT-001 summit", nil},
+		{"QRT", nil},
+	}
+	for _, tt := range tests {
+		if got := ParseReferences(tt.text); !slices.Equal(got, tt.want) {
+			t.Errorf("ParseReferences(%q) = %v, want %v", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestReferences(t *testing.T) {
+	s := Spot{Reference: "US-4491", Comments: "3fer US-4491 US-4816 US-0001"}
+	if got := s.References(); !slices.Equal(got, []string{"US-4491", "US-4816", "US-0001"}) {
+		t.Errorf("References mismatch: %v", got)
+	}
+}
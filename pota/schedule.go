@@ -0,0 +1,107 @@
+package pota
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ScheduleURL is the POTA API endpoint listing the scheduled activations.
+var ScheduleURL = "https://api.pota.app/activation"
+
+// defaultDuration is the length of scheduled activations without an end.
+const defaultDuration = 2 * time.Hour
+
+// Activation is a scheduled activation, as returned by the POTA API.
+type Activation struct {
+	ScheduledActivitiesID int    `json:"scheduledActivitiesId"`
+	ScheduledBy           string `json:"scheduledBy"`
+	Activator             string `json:"activator"`
+	Name                  string `json:"name"`
+	Reference             string `json:"reference"`
+	LocationDesc          string `json:"locationDesc"`
+	StartDate             string `json:"startDate"`
+	EndDate               string `json:"endDate"`
+	StartTime             string `json:"startTime"`
+	EndTime               string `json:"endTime"`
+	Frequencies           string `json:"frequencies"`
+	Comments              string `json:"comments"`
+}
+
+// ListActivations returns the scheduled POTA activations.
+func ListActivations() ([]Activation, error) {
+	resp, err := http.Get(ScheduleURL)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to list POTA scheduled activations: %s", resp.Status)
+	}
+	var activations []Activation
+	if err := json.NewDecoder(resp.Body).Decode(&activations); err != nil {
+		return nil, fmt.Errorf("failed to parse POTA scheduled activations: %w", err)
+	}
+	return activations, nil
+}
+
+// Times returns the planned start and end of the activation, in UTC. The
+// end defaults to 2 hours after the start, and an end time before the start
+// time is on the next day.
+func (a Activation) Times() (start, end time.Time, err error) {
+	start, err = time.Parse("2006-01-02 15:04", a.StartDate+" "+a.StartTime)
+	if err != nil {
+		return start, end, fmt.Errorf("invalid start of scheduled activation %d: %q %q", a.ScheduledActivitiesID, a.StartDate, a.StartTime)
+	}
+	if a.EndTime == "" {
+		return start, start.Add(defaultDuration), nil
+	}
+	endDate := a.EndDate
+	if endDate == "" {
+		endDate = a.StartDate
+	}
+	end, err = time.Parse("2006-01-02 15:04", endDate+" "+a.EndTime)
+	if err != nil {
+		return start, start.Add(defaultDuration), nil
+	}
+	if !end.After(start) {
+		end = end.AddDate(0, 0, 1)
+	}
+	return start, end, nil
+}
+
+// ParseFrequencies splits the frequencies of the activation, written freely
+// by the activators (e.g. "14062 CW, 7.032 cw" or "14.285-SSB"), into
+// frequencies in MHz and their modes. Values above 1000 are in kHz.
+func (a Activation) ParseFrequencies() (mhz []float64, modes []string) {
+	fields := strings.FieldsFunc(a.Frequencies, func(r rune) bool {
+		return r == ',' || r == ';' || r == '/' || r == '-' || r == ' '
+	})
+	for _, field := range fields {
+		f, err := strconv.ParseFloat(strings.TrimSuffix(strings.ToLower(field), "khz"), 64)
+		if err != nil {
+			// A mode following the frequency
+			if len(mhz) > len(modes) {
+				modes = append(modes, strings.ToUpper(field))
+			}
+			continue
+		}
+		if f <= 0 {
+			continue
+		}
+		if len(mhz) > len(modes) {
+			modes = append(modes, "")
+		}
+		if f > 1000 {
+			f /= 1000
+		}
+		mhz = append(mhz, f)
+	}
+	if len(mhz) > len(modes) {
+		modes = append(modes, "")
+	}
+	return mhz, modes
+}
@@ -0,0 +1,55 @@
+package pota
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestListActivations(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"scheduledActivitiesId":42,"activator":"KN6YUH","name":"Big Basin Redwoods State Park","reference":"US-1234","locationDesc":"US-CA","startDate":"2026-10-20","endDate":"2026-10-20","startTime":"23:00","endTime":"01:00","frequencies":"14062 CW, 7.032-cw, 14285","comments":"Evening"}]`))
+	}))
+	defer server.Close()
+	defer func(url string) { ScheduleURL = url }(ScheduleURL)
+	ScheduleURL = server.URL
+
+	activations, err := ListActivations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(activations) != 1 || activations[0].ScheduledActivitiesID != 42 {
+		t.Fatalf("Unexpected activations: %+v", activations)
+	}
+	a := activations[0]
+
+	start, end, err := a.Times()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !start.Equal(time.Date(2026, 10, 20, 23, 0, 0, 0, time.UTC)) || !end.Equal(time.Date(2026, 10, 21, 1, 0, 0, 0, time.UTC)) {
+		t.Errorf("Times() = %v, %v", start, end)
+	}
+
+	mhz, modes := a.ParseFrequencies()
+	if !slices.Equal(mhz, []float64{14.062, 7.032, 14.285}) || !slices.Equal(modes, []string{"CW", "CW", ""}) {
+		t.Errorf("ParseFrequencies() = %v, %v", mhz, modes)
+	}
+
+	server.Config.Handler = http.NotFoundHandler()
+	if _, err := ListActivations(); err == nil {
+		t.Error("ListActivations should fail on an error status")
+	}
+}
+
+func TestTimesWithoutEnd(t *testing.T) {
+	start, end, err := Activation{StartDate: "2026-10-20", StartTime: "16:00"}.Times()
+	if err != nil || end.Sub(start) != defaultDuration {
+		t.Errorf("Times() = %v, %v, %v", start, end, err)
+	}
+	if _, _, err := (Activation{StartDate: "tomorrow"}).Times(); err == nil {
+		t.Error("Times should fail on an invalid date")
+	}
+}
@@ -0,0 +1,132 @@
+// This package parses the mappings from spot filters (band, mode, program
+// and SOTA points) to the Discord roles mentioned when a spot matches.
+package roles
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/PAARA-org/PAARAbot/bandplan"
+)
+
+// Rule maps a set of filters to a Discord role. Empty filters match any spot.
+type Rule struct {
+	RoleID    string
+	Bands     []string
+	Modes     []string
+	Programs  []string
+	MinPoints int
+}
+
+// ParseFile parses a file with one rule per line: a Discord role ID
+// followed by space separated filters, e.g.
+//
+//	123456789012345678 band:20m mode:CW
+//	234567890123456789 program:SOTA points:8
+//	345678901234567890 band:40m,20m program:POTA
+//
+// Empty lines, and lines commented with # or //, are ignored.
+func ParseFile(filePath string) ([]Rule, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file %s: %w", filePath, err)
+	}
+	defer file.Close()
+
+	var rules []Rule
+	scanner := bufio.NewScanner(file)
+	line := 0
+	for scanner.Scan() {
+		line++
+		trimmedLine := strings.TrimSpace(scanner.Text())
+		if trimmedLine == "" || strings.HasPrefix(trimmedLine, "#") || strings.HasPrefix(trimmedLine, "//") {
+			continue
+		}
+		rule, err := ParseRule(trimmedLine)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		rules = append(rules, rule)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading file: %w", err)
+	}
+	return rules, nil
+}
+
+// ParseRule parses a single rule, formatted as described in ParseFile.
+func ParseRule(line string) (Rule, error) {
+	fields := strings.Fields(line)
+	if len(fields) < 2 {
+		return Rule{}, fmt.Errorf("expected a role ID followed by filters, got %q", line)
+	}
+	rule := Rule{RoleID: fields[0]}
+	for _, f := range fields[1:] {
+		key, value, found := strings.Cut(f, ":")
+		if !found || value == "" {
+			return Rule{}, fmt.Errorf("expected key:value filter, got %q", f)
+		}
+		values := strings.Split(strings.ToUpper(value), ",")
+		switch strings.ToLower(key) {
+		case "band":
+			rule.Bands = append(rule.Bands, values...)
+		case "mode":
+			rule.Modes = append(rule.Modes, values...)
+		case "program":
+			rule.Programs = append(rule.Programs, values...)
+		case "points":
+			points, err := strconv.Atoi(value)
+			if err != nil {
+				return Rule{}, fmt.Errorf("invalid points %q: %w", value, err)
+			}
+			rule.MinPoints = points
+		default:
+			return Rule{}, fmt.Errorf("unknown filter %q", key)
+		}
+	}
+	return rule, nil
+}
+
+// Matches reports whether a spot with these properties matches all the
+// rule's filters. The comparisons are case insensitive, and modes match
+// either their canonical name (e.g. "FT-8" matches FT8) or their family
+// (e.g. FT8 matches DATA).
+func (r Rule) Matches(program, band, mode string, points int) bool {
+	return matchesAny(r.Programs, program) &&
+		matchesAny(r.Bands, band) &&
+		matchesMode(r.Modes, mode) &&
+		points >= r.MinPoints
+}
+
+// RoleIDs returns the unique role IDs of the rules.
+func RoleIDs(rules []Rule) []string {
+	var ids []string
+	for _, r := range rules {
+		if !slices.Contains(ids, r.RoleID) {
+			ids = append(ids, r.RoleID)
+		}
+	}
+	return ids
+}
+
+func matchesAny(values []string, v string) bool {
+	if len(values) == 0 {
+		return true
+	}
+	return slices.Contains(values, strings.ToUpper(strings.TrimSpace(v)))
+}
+
+func matchesMode(values []string, mode string) bool {
+	if len(values) == 0 {
+		return true
+	}
+	normalized := bandplan.NormalizeMode(mode)
+	family := bandplan.ModeFamily(mode)
+	return slices.ContainsFunc(values, func(v string) bool {
+		return v == family || bandplan.NormalizeMode(v) == normalized
+	})
+}
@@ -0,0 +1,76 @@
+package roles
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestParseFile(t *testing.T) {
+	content := `
+# Role ID            filters
+111 band:20m mode:CW
+// SOTA activations worth at least 8 points
+222 program:SOTA points:8
+333 band:40m,20m program:pota
+111 band:40m mode:cw
+`
+	tmpFile := filepath.Join(t.TempDir(), "roles.txt")
+	if err := os.WriteFile(tmpFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write temp file: %v", err)
+	}
+
+	rules, err := ParseFile(tmpFile)
+	if err != nil {
+		t.Fatalf("ParseFile failed: %v", err)
+	}
+	if len(rules) != 4 {
+		t.Fatalf("Expected 4 rules, got %d", len(rules))
+	}
+	if got := RoleIDs(rules); !slices.Equal(got, []string{"111", "222", "333"}) {
+		t.Errorf("RoleIDs mismatch: %v", got)
+	}
+
+	tests := []struct {
+		name    string
+		rule    Rule
+		program string
+		band    string
+		mode    string
+		points  int
+		want    bool
+	}{
+		{"band and mode", rules[0], "POTA", "20m", "cw", 0, true},
+		{"wrong mode", rules[0], "POTA", "20m", "SSB", 0, false},
+		{"enough points", rules[1], "SOTA", "2m", "FM", 8, true},
+		{"not enough points", rules[1], "SOTA", "2m", "FM", 6, false},
+		{"wrong program", rules[1], "POTA", "2m", "FM", 10, false},
+		{"band list", rules[2], "POTA", "40m", "FT8", 0, true},
+		{"unknown band", rules[2], "POTA", "", "FT8", 0, false},
+		{"mode spelling", rules[0], "POTA", "20m", "cw ", 0, true},
+		{"mode family", Rule{Modes: []string{"PHONE"}}, "SOTA", "2m", "FM", 0, true},
+		{"mode alias", Rule{Modes: []string{"FT8"}}, "POTA", "20m", "FT-8", 0, true},
+		{"other family", Rule{Modes: []string{"DATA"}}, "POTA", "20m", "SSB", 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.rule.Matches(tt.program, tt.band, tt.mode, tt.points); got != tt.want {
+				t.Errorf("Matches = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseRuleErrors(t *testing.T) {
+	for _, line := range []string{
+		"111",
+		"111 band",
+		"111 color:red",
+		"111 points:many",
+	} {
+		if _, err := ParseRule(line); err == nil {
+			t.Errorf("ParseRule(%q) should fail", line)
+		}
+	}
+}
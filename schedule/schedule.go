@@ -0,0 +1,168 @@
+// This package implements cron-like schedules, used to post the digests at
+// configurable times.
+package schedule
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// shorthands are the supported predefined schedules.
+var shorthands = map[string]string{
+	"@hourly":  "0 * * * *",
+	"@daily":   "0 0 * * *",
+	"@weekly":  "0 0 * * 0",
+	"@monthly": "0 0 1 * *",
+}
+
+// field is the range of a cron field.
+type field struct {
+	name     string
+	min, max int
+}
+
+var fields = []field{
+	{"minute", 0, 59},
+	{"hour", 0, 23},
+	{"day of month", 1, 31},
+	{"month", 1, 12},
+	{"day of week", 0, 6},
+}
+
+// Schedule is a parsed cron expression, matching times in their own
+// location (i.e. in local time).
+type Schedule struct {
+	// sets holds a bitset of the allowed values for each field.
+	sets [5]uint64
+	// anyDom and anyDow are set when the day of month or the day of week is
+	// "*". When both are restricted, as in cron, a day matches either.
+	anyDom, anyDow bool
+	expr           string
+}
+
+// Parse parses a cron expression with 5 fields: minute, hour, day of month,
+// month and day of week (0 or 7 is Sunday). Fields are "*", a value, a range
+// ("1-5"), a step ("*/15" or "0-30/10"), or a comma separated list of those.
+// The @hourly, @daily, @weekly and @monthly shorthands are also accepted.
+//
+//	0 21 * * *    every day at 21:00
+//	30 8 * * 1    every Monday at 08:30
+//	0 */6 * * *   every 6 hours
+func Parse(expr string) (Schedule, error) {
+	expr = strings.TrimSpace(expr)
+	s := Schedule{expr: expr}
+	if full, ok := shorthands[strings.ToLower(expr)]; ok {
+		expr = full
+	}
+	parts := strings.Fields(expr)
+	if len(parts) != len(fields) {
+		return Schedule{}, fmt.Errorf("invalid schedule %q, expected 5 fields: minute hour day-of-month month day-of-week", s.expr)
+	}
+	for i, part := range parts {
+		f := fields[i]
+		if f.name == "day of week" {
+			// 7 is also Sunday
+			f.max = 7
+		}
+		set, err := parseField(part, f)
+		if err != nil {
+			return Schedule{}, fmt.Errorf("invalid schedule %q: %w", s.expr, err)
+		}
+		s.sets[i] = set
+	}
+	if s.sets[4]&(1<<7) != 0 {
+		s.sets[4] |= 1
+	}
+	s.anyDom = parts[2] == "*"
+	s.anyDow = parts[4] == "*"
+	return s, nil
+}
+
+// parseField returns the bitset of the values allowed by a field.
+func parseField(part string, f field) (uint64, error) {
+	var set uint64
+	for _, item := range strings.Split(part, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(item, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepPart)
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("invalid step %q in %s", stepPart, f.name)
+			}
+			step = n
+		}
+
+		low, high := f.min, f.max
+		if rangePart != "*" {
+			lowPart, highPart, isRange := strings.Cut(rangePart, "-")
+			var err error
+			if low, err = strconv.Atoi(lowPart); err != nil {
+				return 0, fmt.Errorf("invalid value %q in %s", lowPart, f.name)
+			}
+			high = low
+			if isRange {
+				if high, err = strconv.Atoi(highPart); err != nil {
+					return 0, fmt.Errorf("invalid value %q in %s", highPart, f.name)
+				}
+			} else if hasStep {
+				high = f.max
+			}
+		}
+		if low < f.min || high > f.max || low > high {
+			return 0, fmt.Errorf("%s %q out of range %d-%d", f.name, item, f.min, f.max)
+		}
+		for v := low; v <= high; v += step {
+			set |= 1 << v
+		}
+	}
+	return set, nil
+}
+
+func (s Schedule) has(i, v int) bool {
+	return s.sets[i]&(1<<v) != 0
+}
+
+// dayMatches reports whether the schedule runs on the day of t.
+func (s Schedule) dayMatches(t time.Time) bool {
+	dom, dow := s.has(2, t.Day()), s.has(4, int(t.Weekday()))
+	switch {
+	case s.anyDom && s.anyDow:
+		return true
+	case s.anyDom:
+		return dow
+	case s.anyDow:
+		return dom
+	default:
+		return dom || dow
+	}
+}
+
+// Next returns the first time strictly after t matching the schedule, in
+// t's location. It returns the zero time if there is none within 5 years
+// (e.g. for February 30th).
+func (s Schedule) Next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+	for t.Before(limit) {
+		switch {
+		case !s.has(3, int(t.Month())):
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+		case !s.dayMatches(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+		case !s.has(1, t.Hour()):
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+		case !s.has(0, t.Minute()):
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// String returns the expression the schedule was parsed from.
+func (s Schedule) String() string {
+	return s.expr
+}
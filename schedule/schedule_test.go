@@ -0,0 +1,51 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNext(t *testing.T) {
+	la, err := time.LoadLocation("America/Los_Angeles")
+	if err != nil {
+		t.Skip("No timezone database:", err)
+	}
+	// Sunday October 18th 2026, 21:30 local time
+	now := time.Date(2026, 10, 18, 21, 30, 15, 0, la)
+
+	tests := []struct {
+		expr string
+		want time.Time
+	}{
+		{"0 21 * * *", time.Date(2026, 10, 19, 21, 0, 0, 0, la)},
+		{"45 21 * * *", time.Date(2026, 10, 18, 21, 45, 0, 0, la)},
+		{"30 8 * * 1", time.Date(2026, 10, 19, 8, 30, 0, 0, la)},
+		{"0 9 * * 7", time.Date(2026, 10, 25, 9, 0, 0, 0, la)},
+		{"*/15 * * * *", time.Date(2026, 10, 18, 21, 45, 0, 0, la)},
+		{"0 0-6/3 * * *", time.Date(2026, 10, 19, 0, 0, 0, 0, la)},
+		{"0 8 1,15 * *", time.Date(2026, 11, 1, 8, 0, 0, 0, la)},
+		// Day of month or day of week, as in cron
+		{"0 8 15 * 2", time.Date(2026, 10, 20, 8, 0, 0, 0, la)},
+		{"@weekly", time.Date(2026, 10, 25, 0, 0, 0, 0, la)},
+		{"@monthly", time.Date(2026, 11, 1, 0, 0, 0, 0, la)},
+		{"0 0 30 2 *", time.Time{}},
+	}
+	for _, tt := range tests {
+		s, err := Parse(tt.expr)
+		if err != nil {
+			t.Errorf("Parse(%q) failed: %v", tt.expr, err)
+			continue
+		}
+		if got := s.Next(now); !got.Equal(tt.want) {
+			t.Errorf("Next(%q) = %v, want %v", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, expr := range []string{"", "0 21 * *", "60 * * * *", "0 24 * * *", "* * 0 * *", "0 8 * * 8", "*/0 * * * *", "5-1 * * * *", "a * * * *"} {
+		if _, err := Parse(expr); err == nil {
+			t.Errorf("Parse(%q) should fail", expr)
+		}
+	}
+}
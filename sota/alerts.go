@@ -0,0 +1,82 @@
+package sota
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// AlertsURL is the SOTA API endpoint listing the upcoming alerts.
+var AlertsURL = "https://api2.sota.org.uk/api/alerts"
+
+// Alert is a planned activation, as returned by the SOTA alerts API.
+type Alert struct {
+	Id                 int    `json:"id"`
+	UserID             int    `json:"userID"`
+	TimeStamp          string `json:"timeStamp"`
+	DateActivated      string `json:"dateActivated"`
+	AssociationCode    string `json:"associationCode"`
+	SummitCode         string `json:"summitCode"`
+	SummitDetails      string `json:"summitDetails"`
+	Frequency          string `json:"frequency"`
+	Comments           string `json:"comments"`
+	ActivatingCallsign string `json:"activatingCallsign"`
+	ActivatorName      string `json:"activatorName"`
+	PosterCallsign     string `json:"posterCallsign"`
+	Epoch              string `json:"epoch"`
+}
+
+// ListAlerts returns the upcoming SOTA alerts.
+func ListAlerts() ([]Alert, error) {
+	resp, err := http.Get(AlertsURL)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to list SOTA alerts: %s", resp.Status)
+	}
+	var alerts []Alert
+	if err := json.NewDecoder(resp.Body).Decode(&alerts); err != nil {
+		return nil, fmt.Errorf("failed to parse SOTA alerts: %w", err)
+	}
+	return alerts, nil
+}
+
+// Summit returns the full summit code of the alert, e.g. W6/CT-001. The API
+// may return the association separately.
+func (a Alert) Summit() string {
+	code := strings.ToUpper(strings.TrimSpace(a.SummitCode))
+	if a.AssociationCode != "" && !strings.Contains(code, "/") {
+		code = strings.ToUpper(strings.TrimSpace(a.AssociationCode)) + "/" + code
+	}
+	return code
+}
+
+// Time returns the planned start of the activation, in UTC.
+func (a Alert) Time() (time.Time, error) {
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04:05"} {
+		if t, err := time.Parse(layout, a.DateActivated); err == nil {
+			return t.UTC(), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid alert time %q", a.DateActivated)
+}
+
+// Frequencies splits the frequencies of the alert, written as
+// "14.062-cw, 7.032-cw", into frequencies in MHz and modes. Entries which
+// aren't a frequency are skipped.
+func (a Alert) Frequencies() (mhz []float64, modes []string) {
+	for _, part := range strings.FieldsFunc(a.Frequency, func(r rune) bool { return r == ',' || r == ';' || r == ' ' }) {
+		freq, mode, _ := strings.Cut(part, "-")
+		var f float64
+		if _, err := fmt.Sscan(freq, &f); err != nil || f <= 0 {
+			continue
+		}
+		mhz = append(mhz, f)
+		modes = append(modes, strings.ToUpper(mode))
+	}
+	return mhz, modes
+}
@@ -0,0 +1,43 @@
+package sota
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestListAlerts(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"id":123,"dateActivated":"2026-10-20T16:00:00","associationCode":"W6","summitCode":"CT-001","summitDetails":"Mount Umunhum, 1155m, 4 Points","frequency":"14.062-cw, 7.032-cw, 146.52-fm","comments":"Weather permitting","activatingCallsign":"KN6YUH/P"}]`))
+	}))
+	defer server.Close()
+	defer func(url string) { AlertsURL = url }(AlertsURL)
+	AlertsURL = server.URL
+
+	alerts, err := ListAlerts()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(alerts) != 1 {
+		t.Fatalf("Expected 1 alert, got %d", len(alerts))
+	}
+	a := alerts[0]
+	if a.Summit() != "W6/CT-001" {
+		t.Errorf("Summit() = %s", a.Summit())
+	}
+	if got, err := a.Time(); err != nil || !got.Equal(time.Date(2026, 10, 20, 16, 0, 0, 0, time.UTC)) {
+		t.Errorf("Time() = %v, %v", got, err)
+	}
+	mhz, modes := a.Frequencies()
+	if !slices.Equal(mhz, []float64{14.062, 7.032, 146.52}) || !slices.Equal(modes, []string{"CW", "CW", "FM"}) {
+		t.Errorf("Frequencies() = %v, %v", mhz, modes)
+	}
+
+	AlertsURL = server.URL + "/missing"
+	server.Config.Handler = http.NotFoundHandler()
+	if _, err := ListAlerts(); err == nil {
+		t.Error("ListAlerts should fail on an error status")
+	}
+}
@@ -8,10 +8,14 @@ import (
 	"io"
 	"net/http"
 	"os"
+	"regexp"
+	"slices"
 	"strings"
+	"sync"
 )
 
-type SotaSpots []struct {
+// Spot is a single SOTA spot as returned by the API.
+type Spot struct {
 	Id                int     `json:"id"`
 	UserID            int     `json:"userID"`
 	TimeStamp         string  `json:"timeStamp"`
@@ -30,19 +34,62 @@ type SotaSpots []struct {
 	Epoch             string  `json:"epoch"`
 }
 
-// PotaMapping stores results for POTA parks
+type SotaSpots []Spot
+
+// PotaMapping stores results for POTA parks. Summits located in several
+// parks have several ParkIds, while ParkId keeps them joined with "/" as
+// found in the CSV (e.g. US-4491/US-4816).
 type PotaMapping struct {
-	IsPota   bool
-	ParkId   string
-	ParkName string
+	IsPota    bool
+	ParkId    string
+	ParkName  string
+	ParkIds   []string
+	ParkNames []string
 }
 
 // sotaPota is a dictionary with key=SOTA peak and value=POTA details
 type sotaPota = map[string]PotaMapping
 
 // SotaPotaMappings is a global variable storing the dictionary with
-// all the sota-pota mappings
-var SotaPotaMappings = make(map[string]PotaMapping)
+// all the sota-pota mappings. It's replaced as a whole by SetMappings, and
+// must only be read through IsPota.
+var (
+	SotaPotaMappings = make(map[string]PotaMapping)
+	mappingsMu       sync.RWMutex
+)
+
+// parkSummits is the reverse index of SotaPotaMappings: the summits in each
+// park, sorted by summit code.
+var parkSummits = make(map[string][]string)
+
+// SetMappings replaces the sota-pota mappings in a thread-safe manner.
+func SetMappings(mappings map[string]PotaMapping) {
+	normalized := make(sotaPota, len(mappings))
+	reverse := make(map[string][]string)
+	for summitCode, m := range mappings {
+		summitCode = normalizeCode(summitCode)
+		normalized[summitCode] = m
+		for _, parkId := range m.ParkIds {
+			parkId = normalizeCode(parkId)
+			reverse[parkId] = append(reverse[parkId], summitCode)
+		}
+	}
+	for _, summitCodes := range reverse {
+		slices.Sort(summitCodes)
+	}
+
+	mappingsMu.Lock()
+	defer mappingsMu.Unlock()
+	SotaPotaMappings = normalized
+	parkSummits = reverse
+}
+
+// MappingsCount returns the number of sota-pota mappings.
+func MappingsCount() int {
+	mappingsMu.RLock()
+	defer mappingsMu.RUnlock()
+	return len(SotaPotaMappings)
+}
 
 func ListSpots() (result SotaSpots, err error) {
 	// -1 is spots in the last hour
@@ -66,21 +113,43 @@ func ListSpots() (result SotaSpots, err error) {
 // and returns a dictionary with only peaks that have associated POTA parks
 func ParseSotaCSV(filePath string) (mappings sotaPota) {
 	fmt.Printf("Starting to parse %s\n", filePath)
-	mappings = make(sotaPota)
 	file, err := os.Open(filePath)
 	if err != nil {
 		fmt.Printf("Error opening file: %v\n", err)
-		return
+		return make(sotaPota)
 	}
 	defer file.Close()
-	reader := csv.NewReader(file)
+
+	mappings, err = ParsePotaMappings(file)
+	if err != nil {
+		fmt.Printf("Error reading CSV: %v\n", err)
+		return make(sotaPota)
+	}
+	fmt.Printf("Finished parsing the CSV, stored %d mappings\n", len(mappings))
+	return
+}
+
+// ParsePotaMappings parses the SOTA to POTA mapping CSV read from r, as
+// described in ParseSotaCSV. The first line is skipped as the header.
+func ParsePotaMappings(r io.Reader) (sotaPota, error) {
+	return parsePotaMappings(r, false)
+}
+
+// parsePotaMappings parses the mappings as ParsePotaMappings. With
+// checkHeader, the CSV must start with the header of the SOTA export, which
+// tells the downloaded files apart from e.g. error pages.
+func parsePotaMappings(r io.Reader, checkHeader bool) (sotaPota, error) {
+	mappings := make(sotaPota)
+	reader := csv.NewReader(r)
 	reader.FieldsPerRecord = -1 // Allow variable fields
 
 	// Read all records
 	records, err := reader.ReadAll()
 	if err != nil {
-		fmt.Printf("Error reading CSV: %v\n", err)
-		return
+		return nil, err
+	}
+	if checkHeader && (len(records) == 0 || len(records[0]) < 19 || strings.TrimSpace(records[0][0]) != "SummitCode") {
+		return nil, fmt.Errorf("unexpected header, expected SummitCode and at least 19 columns")
 	}
 
 	// Process records starting from index 1 (skip header)
@@ -102,13 +171,60 @@ func ParseSotaCSV(filePath string) (mappings sotaPota) {
 				ParkId:   strings.TrimSpace(record[18]),
 				ParkName: strings.TrimSpace(record[17]),
 			}
+			results.ParkIds = splitList(results.ParkId)
+			// Park names can contain a "/", so they're only split when there is
+			// one name per park.
+			results.ParkNames = []string{results.ParkName}
+			if names := splitList(results.ParkName); len(names) == len(results.ParkIds) {
+				results.ParkNames = names
+			}
 			mappings[peakId] = results
 		}
 	}
-	fmt.Printf("Finished parsing the CSV, stored %d mappings\n", len(mappings))
-	return
+	return mappings, nil
+}
+
+// splitList splits a "/" separated list, trimming the values.
+func splitList(s string) []string {
+	var values []string
+	for _, v := range strings.Split(s, "/") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// SummitsIn returns the codes of the summits located in the POTA park.
+func SummitsIn(parkId string) []string {
+	mappingsMu.RLock()
+	defer mappingsMu.RUnlock()
+	return slices.Clone(parkSummits[normalizeCode(parkId)])
+}
+
+// normalizeCode returns the canonical form of a summit or park code, as
+// stored in the mappings.
+func normalizeCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// summitCodePattern matches summit codes, e.g. W6/CT-001 or G/LD-003.
+var summitCodePattern = regexp.MustCompile(`\b[A-Z0-9]{1,4}/[A-Z0-9]{2}-[0-9]{3}\b`)
+
+// ParseSummitCodes returns the summit codes mentioned in the text (e.g. the
+// comments of a POTA spot), in order and without duplicates.
+func ParseSummitCodes(text string) []string {
+	var codes []string
+	for _, code := range summitCodePattern.FindAllString(strings.ToUpper(text), -1) {
+		if !slices.Contains(codes, code) {
+			codes = append(codes, code)
+		}
+	}
+	return codes
 }
 
 func IsPota(summitCode string) PotaMapping {
-	return SotaPotaMappings[summitCode]
+	mappingsMu.RLock()
+	defer mappingsMu.RUnlock()
+	return SotaPotaMappings[normalizeCode(summitCode)]
 }
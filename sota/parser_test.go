@@ -3,6 +3,8 @@ package sota
 import (
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
 )
 
@@ -39,6 +41,26 @@ JW/VS-597,Svalbard,Nordvest-Spitsbergen,Kobben,265,869,10.9012,79.6937,10.9012,7
 		}
 	})
 
+	t.Run("MultiplePotaIds", func(t *testing.T) {
+		mapping := mappings["K0M/NE-002"]
+		if len(mapping.ParkIds) != 2 || mapping.ParkIds[0] != "US-4491" || mapping.ParkIds[1] != "US-4816" {
+			t.Errorf("Incorrect park IDs: got %v", mapping.ParkIds)
+		}
+		if len(mapping.ParkNames) != 2 || mapping.ParkNames[1] != "Pat Bayle State Forest" {
+			t.Errorf("Incorrect park names: got %v", mapping.ParkNames)
+		}
+		if mapping.ParkId != "US-4491/US-4816" {
+			t.Errorf("ParkId should keep the joined IDs: got %v", mapping.ParkId)
+		}
+	})
+
+	t.Run("ParkNameWithSlash", func(t *testing.T) {
+		mapping := mappings["K0M/NE-003"]
+		if len(mapping.ParkNames) != 1 || mapping.ParkNames[0] != mapping.ParkName {
+			t.Errorf("Single park name should not be split: got %v", mapping.ParkNames)
+		}
+	})
+
 	t.Run("MissingPotaId", func(t *testing.T) {
 		if _, exists := mappings["G/LD-002"]; exists {
 			t.Error("Record with empty POTA ID should be skipped")
@@ -73,3 +95,50 @@ JW/VS-597,Svalbard,Nordvest-Spitsbergen,Kobben,265,869,10.9012,79.6937,10.9012,7
 		}
 	})
 }
+
+func TestSummitsIn(t *testing.T) {
+	mappings, err := ParsePotaMappings(strings.NewReader(testMappings))
+	if err != nil {
+		t.Fatal(err)
+	}
+	SetMappings(mappings)
+	defer SetMappings(make(map[string]PotaMapping))
+
+	if got := SummitsIn("US-4491"); !slices.Equal(got, []string{"K0M/NE-002", "K0M/NE-003"}) {
+		t.Errorf("SummitsIn(US-4491) = %v", got)
+	}
+	if got := SummitsIn("us-4816"); !slices.Equal(got, []string{"K0M/NE-002"}) {
+		t.Errorf("SummitsIn(us-4816) = %v", got)
+	}
+	if got := SummitsIn("US-0001"); len(got) != 0 {
+		t.Errorf("SummitsIn(US-0001) = %v", got)
+	}
+	if m := IsPota(" k0m/ne-002 "); !m.IsPota || m.ParkId != "US-4491/US-4816" {
+		t.Errorf("IsPota(k0m/ne-002) = %+v", m)
+	}
+}
+
+func TestParsePotaMappingsHeader(t *testing.T) {
+	// Local files are accepted without the header of the SOTA export
+	local := strings.Replace(testMappings, "SummitCode,", "Summit,", 1)
+	if mappings, err := ParsePotaMappings(strings.NewReader(local)); err != nil || len(mappings) != 2 {
+		t.Errorf("ParsePotaMappings() = %v, %v", mappings, err)
+	}
+	if _, err := parsePotaMappings(strings.NewReader(local), true); err == nil {
+		t.Error("Downloaded files without the header should be rejected")
+	}
+}
+
+func TestParseSummitCodes(t *testing.T) {
+	tests := map[string][]string{
+		"also on W6/CT-001 for SOTA":      {"W6/CT-001"},
+		"w6/ct-001 & W6/CT-002 W6/CT-001": {"W6/CT-001", "W6/CT-002"},
+		"G/LD-003, 2fer US-4491/US-4816":  {"G/LD-003"},
+		"QRT, thanks all":                 nil,
+	}
+	for input, want := range tests {
+		if got := ParseSummitCodes(input); !slices.Equal(got, want) {
+			t.Errorf("ParseSummitCodes(%q) = %v, want %v", input, got, want)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package sota
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// maxShrink is how much smaller (as a fraction) a downloaded list may be than
+// the current one. A list losing more entries is most likely truncated.
+const maxShrink = 0.5
+
+// httpClient downloads the reference lists, which can take a while.
+var httpClient = &http.Client{Timeout: 5 * time.Minute}
+
+// download fetches rawURL and validates its content with parse. Valid
+// content is saved to cachePath, when set, replacing the previous copy
+// atomically.
+func download(rawURL, cachePath string, parse func(io.Reader) error) error {
+	resp, err := httpClient.Get(rawURL)
+	if err != nil {
+		return fmt.Errorf("failed to fetch URL %s: %w", rawURL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("bad status fetching %s: %s", rawURL, resp.Status)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", rawURL, err)
+	}
+	if err := parse(bytes.NewReader(body)); err != nil {
+		return fmt.Errorf("invalid content at %s: %w", rawURL, err)
+	}
+	if cachePath == "" {
+		return nil
+	}
+
+	// Write to a temporary file first, so that the cache is never left
+	// half written.
+	tmp, err := os.CreateTemp(filepath.Dir(cachePath), filepath.Base(cachePath)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to cache %s: %w", rawURL, err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(body); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to cache %s: %w", rawURL, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to cache %s: %w", rawURL, err)
+	}
+	if err := os.Rename(tmp.Name(), cachePath); err != nil {
+		return fmt.Errorf("failed to cache %s: %w", rawURL, err)
+	}
+	return nil
+}
+
+// checkSize refuses a list much smaller than the current one.
+func checkSize(got, current int) error {
+	if got == 0 {
+		return fmt.Errorf("no entries found")
+	}
+	if float64(got) < float64(current)*(1-maxShrink) {
+		return fmt.Errorf("only %d entries, down from %d", got, current)
+	}
+	return nil
+}
+
+// RefreshMappings downloads the sota-pota mappings CSV from rawURL, and
+// replaces the current mappings when it's valid. The CSV is saved to
+// cachePath, so that it can be loaded with ParseSotaCSV on the next start.
+// On failure, the current mappings are kept.
+func RefreshMappings(rawURL, cachePath string) (int, error) {
+	var mappings sotaPota
+	err := download(rawURL, cachePath, func(r io.Reader) (err error) {
+		if mappings, err = parsePotaMappings(r, true); err != nil {
+			return err
+		}
+		return checkSize(len(mappings), MappingsCount())
+	})
+	if err != nil {
+		return 0, err
+	}
+	SetMappings(mappings)
+	return len(mappings), nil
+}
+
+// RefreshSummits downloads the summits list from rawURL, and replaces the
+// current list when it's valid. The list is saved to cachePath, so that it
+// can be loaded with ParseSummitsFile on the next start. On failure, the
+// current list is kept.
+func RefreshSummits(rawURL, cachePath string) (int, error) {
+	var list map[string]Summit
+	err := download(rawURL, cachePath, func(r io.Reader) (err error) {
+		if list, err = ParseSummits(r); err != nil {
+			return err
+		}
+		return checkSize(len(list), SummitsCount())
+	})
+	if err != nil {
+		return 0, err
+	}
+	SetSummits(list)
+	return len(list), nil
+}
@@ -0,0 +1,97 @@
+package sota
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testSummitsList = `SOTA Summits List (Date=19/10/2026)
+SummitCode,AssociationName,RegionName,SummitName,AltM,AltFt,GridRef1,GridRef2,Longitude,Latitude,Points,BonusPoints,ValidFrom,ValidTo,ActivationCount,ActivationDate,ActivationCall
+W6/CT-001,USA - California,Central Coast,Mount Umunhum,1155,3789,-121.8981,37.1606,-121.8981,37.1606,4,0,01/07/2010,31/12/2099,87,12/10/2026,KN6YUH
+W6/CT-002,USA - California,Central Coast,Loma Prieta,1154,3786,-121.8439,37.1114,-121.8439,37.1114,4,0,01/07/2010,31/12/2099,0,,
+`
+
+const testMappings = `SummitCode,AssociationName,RegionName,SummitName,AltM,AltFt,GridRef1,GridRef2,Longitude,Latitude,Points,BonusPoints,ValidFrom,ValidTo,ActivationCount,ActivationDate,ActivationCall,ParkName,Pota
+K0M/NE-002,USA - Minnesota ,Northeast,2266,691,2266,-90.3710,47.9297,-90.371,47.9297,8,3,01/10/2013,31/12/2099,0,,,Superior National Forest/Pat Bayle State Forest,US-4491/US-4816
+K0M/NE-003,USA - Minnesota ,Northeast,Misquah Hills,689,2260,-90.5198,47.9749,-90.5198,47.9749,8,3,01/10/2013,31/12/2099,0,,,Superior National Forest,US-4491
+`
+
+func TestParseSummits(t *testing.T) {
+	list, err := ParseSummits(strings.NewReader(testSummitsList))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, ok := list["W6/CT-001"]
+	if !ok || s.Name != "Mount Umunhum" || s.AltFt != 3789 || s.Points != 4 || s.ActivationCount != 87 || s.Latitude != 37.1606 {
+		t.Errorf("Unexpected summit: %+v", s)
+	}
+	if len(list) != 2 {
+		t.Errorf("Expected 2 summits, got %d", len(list))
+	}
+
+	if _, err := ParseSummits(strings.NewReader("<html>Not found</html>\n<body></body>\n")); err == nil {
+		t.Error("ParseSummits should fail without a header")
+	}
+}
+
+func TestRefreshMappings(t *testing.T) {
+	content := testMappings
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		w.Write([]byte(content))
+	}))
+	defer server.Close()
+	defer SetMappings(make(map[string]PotaMapping))
+
+	cache := filepath.Join(t.TempDir(), "sota_pota.csv")
+	if n, err := RefreshMappings(server.URL, cache); err != nil || n != 2 {
+		t.Fatalf("RefreshMappings() = %d, %v", n, err)
+	}
+	if m := IsPota("K0M/NE-003"); m.ParkId != "US-4491" {
+		t.Errorf("Mappings not swapped: %+v", m)
+	}
+	if cached := ParseSotaCSV(cache); len(cached) != 2 {
+		t.Errorf("Expected 2 cached mappings, got %d", len(cached))
+	}
+
+	// Failures keep the last good copy, in memory and on disk
+	for name, bad := range map[string]struct {
+		status  int
+		content string
+	}{
+		"error status": {http.StatusInternalServerError, testMappings},
+		"not a CSV":    {http.StatusOK, "<html>Maintenance</html>"},
+		"truncated":    {http.StatusOK, strings.SplitAfter(testMappings, "\n")[0]},
+	} {
+		status, content = bad.status, bad.content
+		if _, err := RefreshMappings(server.URL, cache); err == nil {
+			t.Errorf("%s: RefreshMappings should fail", name)
+		}
+		if MappingsCount() != 2 || len(ParseSotaCSV(cache)) != 2 {
+			t.Errorf("%s: the last good copy wasn't kept", name)
+		}
+	}
+	if entries, _ := os.ReadDir(filepath.Dir(cache)); len(entries) != 1 {
+		t.Errorf("Temporary files left behind: %v", entries)
+	}
+}
+
+func TestRefreshSummits(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(testSummitsList))
+	}))
+	defer server.Close()
+	defer SetSummits(make(map[string]Summit))
+
+	if n, err := RefreshSummits(server.URL, ""); err != nil || n != 2 {
+		t.Fatalf("RefreshSummits() = %d, %v", n, err)
+	}
+	if s, ok := LookupSummit("w6/ct-002"); !ok || s.Name != "Loma Prieta" {
+		t.Errorf("LookupSummit() = %+v, %v", s, ok)
+	}
+}
@@ -0,0 +1,126 @@
+package sota
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Summit is a summit of the official SOTA summits list.
+type Summit struct {
+	Code            string
+	Association     string
+	Region          string
+	Name            string
+	AltM            int
+	AltFt           int
+	Longitude       float64
+	Latitude        float64
+	Points          int
+	BonusPoints     int
+	ValidFrom       string
+	ValidTo         string
+	ActivationCount int
+	ActivationDate  string
+	ActivationCall  string
+}
+
+// summits stores the summits list, by summit code.
+var (
+	summits   = make(map[string]Summit)
+	summitsMu sync.RWMutex
+)
+
+// SetSummits replaces the summits list in a thread-safe manner.
+func SetSummits(list map[string]Summit) {
+	summitsMu.Lock()
+	defer summitsMu.Unlock()
+	summits = list
+}
+
+// SummitsCount returns the number of summits in the list.
+func SummitsCount() int {
+	summitsMu.RLock()
+	defer summitsMu.RUnlock()
+	return len(summits)
+}
+
+// LookupSummit returns the summit with the given code, e.g. W6/CT-001.
+func LookupSummit(code string) (Summit, bool) {
+	summitsMu.RLock()
+	defer summitsMu.RUnlock()
+	s, ok := summits[strings.ToUpper(strings.TrimSpace(code))]
+	return s, ok
+}
+
+// ParseSummitsFile parses a local copy of the summits list, such as
+// https://storage.sota.org.uk/summitslist.csv
+func ParseSummitsFile(filePath string) (map[string]Summit, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file %s: %w", filePath, err)
+	}
+	defer file.Close()
+	return ParseSummits(file)
+}
+
+// ParseSummits parses the summits list read from r. The list starts with a
+// "SOTA Summits List (Date=...)" title line, followed by the header:
+//
+//	SummitCode,AssociationName,RegionName,SummitName,AltM,AltFt,GridRef1,GridRef2,Longitude,Latitude,Points,BonusPoints,ValidFrom,ValidTo,ActivationCount,ActivationDate,ActivationCall
+func ParseSummits(r io.Reader) (map[string]Summit, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1 // The title line has a single field
+	reader.LazyQuotes = true
+
+	list := make(map[string]Summit)
+	header := false
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if !header {
+			// Skip the title line, up to the header
+			header = strings.TrimSpace(record[0]) == "SummitCode"
+			if !header && line > 1 {
+				return nil, fmt.Errorf("unexpected header, expected SummitCode")
+			}
+			continue
+		}
+		if len(record) < 17 {
+			fmt.Printf("Skipping malformed summit (line %d): %v\n", line, record)
+			continue
+		}
+
+		s := Summit{
+			Code:           strings.TrimSpace(record[0]),
+			Association:    strings.TrimSpace(record[1]),
+			Region:         strings.TrimSpace(record[2]),
+			Name:           strings.TrimSpace(record[3]),
+			ValidFrom:      record[12],
+			ValidTo:        record[13],
+			ActivationDate: record[15],
+			ActivationCall: record[16],
+		}
+		s.AltM, _ = strconv.Atoi(record[4])
+		s.AltFt, _ = strconv.Atoi(record[5])
+		s.Longitude, _ = strconv.ParseFloat(record[8], 64)
+		s.Latitude, _ = strconv.ParseFloat(record[9], 64)
+		s.Points, _ = strconv.Atoi(record[10])
+		s.BonusPoints, _ = strconv.Atoi(record[11])
+		s.ActivationCount, _ = strconv.Atoi(record[14])
+		list[s.Code] = s
+	}
+	if !header {
+		return nil, fmt.Errorf("no header found, expected SummitCode")
+	}
+	return list, nil
+}
@@ -0,0 +1,337 @@
+// This package computes the activation statistics of the tracked callsigns
+// from the spot history.
+package stats
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/PAARA-org/PAARAbot/hams"
+	"github.com/PAARA-org/PAARAbot/history"
+)
+
+// Activation is a callsign's activation of one or more parks, or of a
+// summit, during one UTC day.
+type Activation struct {
+	Callsign   string
+	Program    string
+	References []string
+	Start, End time.Time
+	Bands      []string
+	Modes      []string
+	Points     int
+	Spots      int
+}
+
+// Duration returns the time between the first and last spot.
+func (a Activation) Duration() time.Duration {
+	return a.End.Sub(a.Start)
+}
+
+// activationKey identifies the activations an entry may belong to: its
+// callsign's base, program, UTC day and one of its references.
+type activationKey struct {
+	callsign, program, day, ref string
+}
+
+// activationKeys returns the keys of the entry, one per reference.
+func activationKeys(e history.Entry) []activationKey {
+	keys := make([]activationKey, 0, len(e.Refs()))
+	for _, ref := range e.Refs() {
+		keys = append(keys, activationKey{hams.BaseCall(e.Callsign), e.Program, e.Time.UTC().Format(time.DateOnly), ref})
+	}
+	return keys
+}
+
+// grouping is a list of activations, indexed by their keys.
+type grouping struct {
+	activations []Activation
+	byKey       map[activationKey]int
+	// byCall lists the activations of each base callsign.
+	byCall map[string][]int
+}
+
+// find returns the index of the activation the entry belongs to, or -1. When
+// it shares references with several activations, the oldest is returned.
+func (g *grouping) find(e history.Entry) int {
+	found := -1
+	for _, key := range activationKeys(e) {
+		if i, ok := g.byKey[key]; ok && (found < 0 || i < found) {
+			found = i
+		}
+	}
+	return found
+}
+
+// group groups the entries, sorted by time, into activations.
+func group(entries []history.Entry) *grouping {
+	g := &grouping{byKey: make(map[activationKey]int), byCall: make(map[string][]int)}
+	for _, e := range entries {
+		i := g.find(e)
+		if i < 0 {
+			g.activations = append(g.activations, Activation{
+				Callsign: hams.BaseCall(e.Callsign),
+				Program:  e.Program,
+				Start:    e.Time,
+			})
+			i = len(g.activations) - 1
+			g.byCall[g.activations[i].Callsign] = append(g.byCall[g.activations[i].Callsign], i)
+		}
+		for _, key := range activationKeys(e) {
+			if _, ok := g.byKey[key]; !ok {
+				g.byKey[key] = i
+			}
+		}
+		a := &g.activations[i]
+		for _, ref := range e.Refs() {
+			a.References = addUnique(a.References, ref)
+		}
+		a.End = e.Time
+		a.Bands = addUnique(a.Bands, e.Band)
+		a.Modes = addUnique(a.Modes, e.Mode)
+		a.Points = max(a.Points, e.Points)
+		a.Spots++
+	}
+	return g
+}
+
+// addUnique appends the value unless it's empty or already listed.
+func addUnique(list []string, value string) []string {
+	if value == "" || slices.Contains(list, value) {
+		return list
+	}
+	return append(list, value)
+}
+
+// Activations groups the entries into activations, in chronological order.
+// Spots of the same callsign and program, sharing a reference on the same
+// UTC day, are the same activation.
+func Activations(entries []history.Entry) []Activation {
+	entries = slices.Clone(entries)
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].Time.Before(entries[j].Time) })
+	return group(entries).activations
+}
+
+// Member sums up the activations of a callsign.
+type Member struct {
+	Callsign    string
+	Activations int
+	// Parks and Summits count the unique references activated.
+	Parks   int
+	Summits int
+	// Points are the SOTA activator points, counting each summit once per
+	// calendar year as in the SOTA rules.
+	Points int
+	// Bands and Modes count the activations per band and mode.
+	Bands map[string]int
+	Modes map[string]int
+	// Longest is the longest activation.
+	Longest Activation
+}
+
+// Summarize returns the statistics of each callsign, the most active first.
+func Summarize(activations []Activation) []Member {
+	byCall := make(map[string]*Member)
+	// activated and scored record the references already counted
+	activated := make(map[string]bool)
+	scored := make(map[string]bool)
+	for _, a := range activations {
+		m, ok := byCall[a.Callsign]
+		if !ok {
+			m = &Member{Callsign: a.Callsign, Bands: make(map[string]int), Modes: make(map[string]int)}
+			byCall[a.Callsign] = m
+		}
+		m.Activations++
+		for _, ref := range a.References {
+			key := a.Callsign + " " + a.Program + " " + ref
+			if activated[key] {
+				continue
+			}
+			activated[key] = true
+			if a.Program == "SOTA" {
+				m.Summits++
+			} else {
+				m.Parks++
+			}
+		}
+		if a.Program == "SOTA" && len(a.References) > 0 {
+			key := fmt.Sprintf("%s %d %s", a.Callsign, a.Start.UTC().Year(), a.References[0])
+			if !scored[key] {
+				scored[key] = true
+				m.Points += a.Points
+			}
+		}
+		for _, band := range a.Bands {
+			m.Bands[band]++
+		}
+		for _, mode := range a.Modes {
+			m.Modes[mode]++
+		}
+		if a.Duration() > m.Longest.Duration() {
+			m.Longest = a
+		}
+	}
+
+	members := make([]Member, 0, len(byCall))
+	for _, m := range byCall {
+		members = append(members, *m)
+	}
+	sort.Slice(members, func(i, j int) bool {
+		a, b := members[i], members[j]
+		if a.Activations != b.Activations {
+			return a.Activations > b.Activations
+		}
+		if a.Points != b.Points {
+			return a.Points > b.Points
+		}
+		return a.Callsign < b.Callsign
+	})
+	return members
+}
+
+// Top returns the keys of the counts, the highest first, e.g. the most used
+// bands.
+func Top(counts map[string]int) []string {
+	keys := make([]string, 0, len(counts))
+	for k := range counts {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if counts[keys[i]] != counts[keys[j]] {
+			return counts[keys[i]] > counts[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+	return keys
+}
+
+// Period returns the start of the named period containing now: "month",
+// "year", or "all" (the zero time).
+func Period(name string, now time.Time) (time.Time, error) {
+	now = now.UTC()
+	switch strings.ToLower(name) {
+	case "", "month":
+		return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC), nil
+	case "year":
+		return time.Date(now.Year(), 1, 1, 0, 0, 0, 0, time.UTC), nil
+	case "all":
+		return time.Time{}, nil
+	}
+	return time.Time{}, fmt.Errorf("unknown period %q, expected month, year or all", name)
+}
+
+// counts formats the counts as "20m:3 40m:1", the highest first.
+func counts(c map[string]int) string {
+	var parts []string
+	for _, k := range Top(c) {
+		parts = append(parts, fmt.Sprintf("%s:%d", k, c[k]))
+	}
+	return strings.Join(parts, " ")
+}
+
+// WriteMembersCSV writes the members' statistics as CSV.
+func WriteMembersCSV(w io.Writer, members []Member) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"callsign", "activations", "parks", "summits", "sota_points", "bands", "modes", "longest_activation", "longest_minutes"})
+	for _, m := range members {
+		cw.Write([]string{
+			m.Callsign,
+			fmt.Sprint(m.Activations),
+			fmt.Sprint(m.Parks),
+			fmt.Sprint(m.Summits),
+			fmt.Sprint(m.Points),
+			counts(m.Bands),
+			counts(m.Modes),
+			strings.Join(m.Longest.References, "/"),
+			fmt.Sprintf("%.0f", m.Longest.Duration().Minutes()),
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteActivationsCSV writes the activations as CSV.
+func WriteActivationsCSV(w io.Writer, activations []Activation) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"callsign", "program", "references", "start", "end", "bands", "modes", "points", "spots"})
+	for _, a := range activations {
+		cw.Write([]string{
+			a.Callsign,
+			a.Program,
+			strings.Join(a.References, "/"),
+			a.Start.UTC().Format(time.RFC3339),
+			a.End.UTC().Format(time.RFC3339),
+			strings.Join(a.Bands, " "),
+			strings.Join(a.Modes, " "),
+			fmt.Sprint(a.Points),
+			fmt.Sprint(a.Spots),
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// Contact is an activator spotting another active one: a park-to-park,
+// summit-to-summit or summit-to-park contact.
+type Contact struct {
+	// A was spotted by B.
+	A, B Activation
+	Time time.Time
+}
+
+// Kind returns the contact type: P2P, S2S or S2P.
+func (c Contact) Kind() string {
+	switch {
+	case c.A.Program == "POTA" && c.B.Program == "POTA":
+		return "P2P"
+	case c.A.Program == "SOTA" && c.B.Program == "SOTA":
+		return "S2S"
+	default:
+		return "S2P"
+	}
+}
+
+// contactSlack is how long before its first spot, or after its last spot, an
+// activator is considered still active.
+const contactSlack = 30 * time.Minute
+
+// Contacts returns the contacts between the activations of the entries, i.e.
+// the spots made by another activator while active. Each pair of
+// activations is listed once.
+func Contacts(entries []history.Entry) []Contact {
+	entries = slices.Clone(entries)
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].Time.Before(entries[j].Time) })
+	g := group(entries)
+	activations := g.activations
+	var contacts []Contact
+	for _, e := range entries {
+		spotter := hams.BaseCall(e.Spotter)
+		if spotter == "" || spotter == hams.BaseCall(e.Callsign) {
+			continue
+		}
+		a := g.find(e)
+		b := -1
+		for _, i := range g.byCall[spotter] {
+			if !e.Time.Before(activations[i].Start.Add(-contactSlack)) && !e.Time.After(activations[i].End.Add(contactSlack)) {
+				b = i
+				break
+			}
+		}
+		if a < 0 || b < 0 {
+			continue
+		}
+		if slices.ContainsFunc(contacts, func(c Contact) bool {
+			return c.A.Start.Equal(activations[a].Start) && c.A.Callsign == activations[a].Callsign && c.B.Callsign == spotter ||
+				c.B.Start.Equal(activations[a].Start) && c.B.Callsign == activations[a].Callsign && c.A.Callsign == spotter
+		}) {
+			continue
+		}
+		contacts = append(contacts, Contact{A: activations[a], B: activations[b], Time: e.Time})
+	}
+	return contacts
+}
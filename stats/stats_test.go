@@ -0,0 +1,101 @@
+package stats
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PAARA-org/PAARAbot/history"
+)
+
+var day = time.Date(2026, 10, 1, 16, 0, 0, 0, time.UTC)
+
+var entries = []history.Entry{
+	{ID: "1", Time: day, Program: "POTA", Callsign: "KN6YUH", Reference: "US-4491", Band: "20m", Mode: "CW"},
+	{ID: "2", Time: day.Add(90 * time.Minute), Program: "POTA", Callsign: "KN6YUH/P", Reference: "US-4816", References: []string{"US-4816", "US-4491"}, Band: "40m", Mode: "CW"},
+	{ID: "3", Time: day.AddDate(0, 0, 1), Program: "POTA", Callsign: "KN6YUH", Reference: "US-4491", Band: "20m", Mode: "SSB"},
+	{ID: "4", Time: day, Program: "SOTA", Callsign: "AK6EU", Reference: "W6/CT-001", Band: "20m", Mode: "CW", Points: 4},
+	{ID: "5", Time: day.AddDate(0, 0, 2), Program: "SOTA", Callsign: "AK6EU", Reference: "W6/CT-001", Band: "20m", Mode: "CW", Points: 4},
+	{ID: "6", Time: day.AddDate(0, 0, 3), Program: "SOTA", Callsign: "AK6EU", Reference: "W6/CT-002", Band: "2m", Mode: "FM", Points: 6},
+}
+
+func TestActivations(t *testing.T) {
+	activations := Activations(entries)
+	if len(activations) != 5 {
+		t.Fatalf("Expected 5 activations, got %d: %+v", len(activations), activations)
+	}
+	a := activations[0]
+	if a.Callsign != "KN6YUH" || strings.Join(a.References, "/") != "US-4491/US-4816" || a.Duration() != 90*time.Minute || a.Spots != 2 {
+		t.Errorf("Unexpected 2-fer activation: %+v", a)
+	}
+	if strings.Join(a.Bands, " ") != "20m 40m" || strings.Join(a.Modes, " ") != "CW" {
+		t.Errorf("Unexpected bands and modes: %v %v", a.Bands, a.Modes)
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	members := Summarize(Activations(entries))
+	if len(members) != 2 {
+		t.Fatalf("Expected 2 members, got %d", len(members))
+	}
+
+	// AK6EU has 3 activations, W6/CT-001 only scores once a year
+	m := members[0]
+	if m.Callsign != "AK6EU" || m.Activations != 3 || m.Summits != 2 || m.Parks != 0 || m.Points != 10 {
+		t.Errorf("Unexpected AK6EU stats: %+v", m)
+	}
+	if top := Top(m.Bands); top[0] != "20m" || m.Bands["20m"] != 2 {
+		t.Errorf("Unexpected bands: %v", m.Bands)
+	}
+
+	m = members[1]
+	if m.Callsign != "KN6YUH" || m.Activations != 2 || m.Parks != 2 || m.Points != 0 || m.Longest.Duration() != 90*time.Minute {
+		t.Errorf("Unexpected KN6YUH stats: %+v", m)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteMembersCSV(&buf, members); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 || lines[2] != "KN6YUH,2,2,0,0,20m:2 40m:1,CW:1 SSB:1,US-4491/US-4816,90" {
+		t.Errorf("Unexpected CSV:\n%s", buf.String())
+	}
+}
+
+func TestPeriod(t *testing.T) {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	if got, _ := Period("month", now); !got.Equal(time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Period(month) = %v", got)
+	}
+	if got, _ := Period("year", now); !got.Equal(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Period(year) = %v", got)
+	}
+	if got, _ := Period("all", now); !got.IsZero() {
+		t.Errorf("Period(all) = %v", got)
+	}
+	if _, err := Period("week", now); err == nil {
+		t.Error("Period(week) should fail")
+	}
+}
+
+func TestContacts(t *testing.T) {
+	entries := []history.Entry{
+		{ID: "1", Time: day, Program: "POTA", Callsign: "KN6YUH", Reference: "US-4491", Spotter: "KN6YUH"},
+		{ID: "2", Time: day.Add(10 * time.Minute), Program: "SOTA", Callsign: "AK6EU/P", Reference: "W6/CT-001", Spotter: "KN6YUH/P"},
+		// Both ways, listed once
+		{ID: "3", Time: day.Add(12 * time.Minute), Program: "POTA", Callsign: "KN6YUH", Reference: "US-4491", Spotter: "AK6EU"},
+		// W6XYZ isn't activating
+		{ID: "4", Time: day.Add(15 * time.Minute), Program: "POTA", Callsign: "KN6YUH", Reference: "US-4491", Spotter: "W6XYZ"},
+		// AK6EU spotted KN6YUH the next day, while not activating
+		{ID: "5", Time: day.AddDate(0, 0, 1), Program: "POTA", Callsign: "KN6YUH", Reference: "US-4491", Spotter: "AK6EU"},
+	}
+	contacts := Contacts(entries)
+	if len(contacts) != 1 {
+		t.Fatalf("Expected 1 contact, got %d: %+v", len(contacts), contacts)
+	}
+	if c := contacts[0]; c.A.Callsign != "AK6EU" || c.B.Callsign != "KN6YUH" || c.Kind() != "S2P" {
+		t.Errorf("Unexpected contact: %+v", c)
+	}
+}
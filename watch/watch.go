@@ -0,0 +1,233 @@
+// This package stores the personal callsign watchlists of Discord users,
+// along with their notification preferences.
+package watch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/PAARA-org/PAARAbot/hams"
+)
+
+// Settings are the notification preferences of a user.
+type Settings struct {
+	// Throttle is how often the same activation can be notified. Zero uses
+	// the store's DefaultThrottle.
+	Throttle time.Duration `json:"throttle"`
+	// QuietStart and QuietEnd are the hours (0-23) between which no
+	// notification is sent. Quiet hours are disabled when both are equal.
+	QuietStart int `json:"quietStart"`
+	QuietEnd   int `json:"quietEnd"`
+	// Timezone is the IANA timezone of the quiet hours, UTC if empty.
+	Timezone string `json:"timezone"`
+}
+
+// user is the persisted state of a user.
+type user struct {
+	CallSigns []string `json:"callsigns"`
+	Settings  Settings `json:"settings"`
+}
+
+// Store holds the watchlists of all users. It is safe for concurrent use.
+type Store struct {
+	// DefaultThrottle is used for users without their own throttle.
+	DefaultThrottle time.Duration
+
+	mu       sync.Mutex
+	path     string
+	users    map[string]*user
+	lastSent map[string]time.Time
+}
+
+// Load reads the watchlists stored in the JSON file at path. A missing file
+// results in an empty store. If path is empty, the watchlists are only kept
+// in memory.
+func Load(path string) (*Store, error) {
+	s := &Store{
+		path:     path,
+		users:    make(map[string]*user),
+		lastSent: make(map[string]time.Time),
+	}
+	if path == "" {
+		return s, nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read watchlists %s: %w", path, err)
+	}
+	if err := json.Unmarshal(data, &s.users); err != nil {
+		return nil, fmt.Errorf("failed to parse watchlists %s: %w", path, err)
+	}
+	return s, nil
+}
+
+// save writes the watchlists to disk. It must be called with the lock held.
+func (s *Store) save() error {
+	if s.path == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(s.users, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write watchlists: %w", err)
+	}
+	return os.Rename(tmp, s.path)
+}
+
+func (s *Store) user(userID string) *user {
+	u, ok := s.users[userID]
+	if !ok {
+		u = &user{}
+		s.users[userID] = u
+	}
+	return u
+}
+
+// Watch adds the callsign to the user's watchlist. It returns false if the
+// callsign was already watched.
+func (s *Store) Watch(userID, call string) (bool, error) {
+	call = strings.ToUpper(strings.TrimSpace(call))
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	u := s.user(userID)
+	if slices.Contains(u.CallSigns, call) {
+		return false, nil
+	}
+	u.CallSigns = append(u.CallSigns, call)
+	return true, s.save()
+}
+
+// Unwatch removes the callsign from the user's watchlist. It returns false
+// if the callsign wasn't watched.
+func (s *Store) Unwatch(userID, call string) (bool, error) {
+	call = strings.ToUpper(strings.TrimSpace(call))
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	u, ok := s.users[userID]
+	if !ok || !slices.Contains(u.CallSigns, call) {
+		return false, nil
+	}
+	u.CallSigns = slices.DeleteFunc(u.CallSigns, func(c string) bool { return c == call })
+	return true, s.save()
+}
+
+// List returns the callsigns watched by the user.
+func (s *Store) List(userID string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if u, ok := s.users[userID]; ok {
+		return slices.Clone(u.CallSigns)
+	}
+	return nil
+}
+
+// Settings returns the notification preferences of the user.
+func (s *Store) Settings(userID string) Settings {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if u, ok := s.users[userID]; ok {
+		return u.Settings
+	}
+	return Settings{}
+}
+
+// SetSettings updates the notification preferences of the user.
+func (s *Store) SetSettings(userID string, settings Settings) error {
+	if settings.Timezone != "" {
+		if _, err := time.LoadLocation(settings.Timezone); err != nil {
+			return fmt.Errorf("unknown timezone %q", settings.Timezone)
+		}
+	}
+	if settings.QuietStart < 0 || settings.QuietStart > 23 || settings.QuietEnd < 0 || settings.QuietEnd > 23 {
+		return fmt.Errorf("quiet hours must be between 0 and 23")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.user(userID).Settings = settings
+	return s.save()
+}
+
+// Watchers returns the IDs of the users watching the callsign, ignoring its
+// prefix and suffix: the watchers of KN6YUH are notified of KN6YUH/P and
+// W6/KN6YUH.
+func (s *Store) Watchers(call string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	base := hams.BaseCall(call)
+	var ids []string
+	for id, u := range s.users {
+		if slices.ContainsFunc(u.CallSigns, func(c string) bool { return hams.BaseCall(c) == base }) {
+			ids = append(ids, id)
+		}
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+// Allow reports whether the user should be notified about the activation
+// identified by key, honoring the user's throttle and quiet hours. A true
+// result is recorded as a notification sent at now.
+func (s *Store) Allow(userID, key string, now time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	settings := s.user(userID).Settings
+	if settings.inQuietHours(now) {
+		return false
+	}
+
+	throttle := settings.Throttle
+	if throttle == 0 {
+		throttle = s.DefaultThrottle
+	}
+	sentKey := userID + "|" + key
+	if last, exists := s.lastSent[sentKey]; exists && now.Sub(last) < throttle {
+		return false
+	}
+	s.lastSent[sentKey] = now
+	s.prune(now)
+	return true
+}
+
+// prune forgets the notifications older than the largest throttle, which
+// can't throttle anything anymore. It must be called with the lock held.
+func (s *Store) prune(now time.Time) {
+	longest := s.DefaultThrottle
+	for _, u := range s.users {
+		longest = max(longest, u.Settings.Throttle)
+	}
+	for key, last := range s.lastSent {
+		if now.Sub(last) >= longest {
+			delete(s.lastSent, key)
+		}
+	}
+}
+
+// inQuietHours reports whether t falls within the quiet hours.
+func (st Settings) inQuietHours(t time.Time) bool {
+	if st.QuietStart == st.QuietEnd {
+		return false
+	}
+	loc, err := time.LoadLocation(st.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+	hour := t.In(loc).Hour()
+	if st.QuietStart < st.QuietEnd {
+		return hour >= st.QuietStart && hour < st.QuietEnd
+	}
+	// Quiet hours spanning midnight, e.g. 22 to 7
+	return hour >= st.QuietStart || hour < st.QuietEnd
+}
@@ -0,0 +1,128 @@
+package watch
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestWatchAndPersist(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "watch.json")
+	s, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	if added, err := s.Watch("u1", " kn6yuh "); err != nil || !added {
+		t.Fatalf("Watch failed: %v %v", added, err)
+	}
+	if added, _ := s.Watch("u1", "KN6YUH"); added {
+		t.Error("Watching the same callsign twice should return false")
+	}
+	s.Watch("u2", "KN6YUH")
+	s.Watch("u2", "AK6EU")
+
+	if got := s.Watchers("kn6yuh"); !slices.Equal(got, []string{"u1", "u2"}) {
+		t.Errorf("Watchers mismatch: %v", got)
+	}
+
+	// Activators are spotted with prefixes and suffixes
+	if got := s.Watchers("KN6YUH/P"); !slices.Equal(got, []string{"u1", "u2"}) {
+		t.Errorf("Watchers of KN6YUH/P mismatch: %v", got)
+	}
+	if got := s.Watchers("W6/KN6YUH"); !slices.Equal(got, []string{"u1", "u2"}) {
+		t.Errorf("Watchers of W6/KN6YUH mismatch: %v", got)
+	}
+	if got := s.Watchers("KN6YU"); len(got) != 0 {
+		t.Errorf("Watchers of KN6YU mismatch: %v", got)
+	}
+
+	if removed, _ := s.Unwatch("u2", "kn6yuh"); !removed {
+		t.Error("Unwatch should remove the callsign")
+	}
+	if removed, _ := s.Unwatch("u3", "KN6YUH"); removed {
+		t.Error("Unwatch of an unknown user should return false")
+	}
+
+	// Reload from disk
+	reloaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+	if got := reloaded.List("u2"); !slices.Equal(got, []string{"AK6EU"}) {
+		t.Errorf("Persisted watchlist mismatch: %v", got)
+	}
+	if got := reloaded.Watchers("KN6YUH"); !slices.Equal(got, []string{"u1"}) {
+		t.Errorf("Persisted watchers mismatch: %v", got)
+	}
+}
+
+func TestAllowThrottle(t *testing.T) {
+	s, _ := Load("")
+	s.DefaultThrottle = time.Hour
+	now := time.Date(2025, 6, 17, 12, 0, 0, 0, time.UTC)
+
+	if !s.Allow("u1", "KN6YUH at US-0001", now) {
+		t.Error("First notification should be allowed")
+	}
+	if s.Allow("u1", "KN6YUH at US-0001", now.Add(30*time.Minute)) {
+		t.Error("Notification within the throttle should be refused")
+	}
+	if !s.Allow("u2", "KN6YUH at US-0001", now.Add(30*time.Minute)) {
+		t.Error("Throttle should be per user")
+	}
+	if !s.Allow("u1", "KN6YUH at US-0001", now.Add(time.Hour)) {
+		t.Error("Notification after the throttle should be allowed")
+	}
+
+	s.SetSettings("u3", Settings{Throttle: 10 * time.Minute})
+	s.Allow("u3", "A", now)
+	if !s.Allow("u3", "A", now.Add(10*time.Minute)) {
+		t.Error("User throttle should override the default")
+	}
+}
+
+func TestAllowPrune(t *testing.T) {
+	s, _ := Load("")
+	s.DefaultThrottle = time.Hour
+	now := time.Date(2025, 6, 17, 12, 0, 0, 0, time.UTC)
+
+	s.Allow("u1", "KN6YUH at US-0001", now)
+	s.Allow("u1", "KN6YUH at US-0002", now.Add(30*time.Minute))
+	s.Allow("u1", "KN6YUH at US-0003", now.Add(time.Hour))
+	if len(s.lastSent) != 2 {
+		t.Errorf("Expected the expired notification to be forgotten: %v", s.lastSent)
+	}
+
+	// The largest throttle of the users is kept
+	s.SetSettings("u2", Settings{Throttle: 3 * time.Hour})
+	s.Allow("u2", "KN6YUH at US-0004", now.Add(time.Hour))
+	s.Allow("u1", "KN6YUH at US-0005", now.Add(3*time.Hour))
+	if _, ok := s.lastSent["u1|KN6YUH at US-0002"]; !ok || len(s.lastSent) != 4 {
+		t.Errorf("Unexpected notifications kept: %v", s.lastSent)
+	}
+	if s.Allow("u2", "KN6YUH at US-0004", now.Add(3*time.Hour)) {
+		t.Error("Notification within the user throttle should be refused")
+	}
+}
+
+func TestQuietHours(t *testing.T) {
+	s, _ := Load("")
+	if err := s.SetSettings("u1", Settings{QuietStart: 22, QuietEnd: 7, Timezone: "America/Los_Angeles"}); err != nil {
+		t.Fatalf("SetSettings failed: %v", err)
+	}
+
+	// 06:00 UTC is 23:00 in Los Angeles (PDT)
+	if s.Allow("u1", "A", time.Date(2025, 6, 17, 6, 0, 0, 0, time.UTC)) {
+		t.Error("Notification during quiet hours should be refused")
+	}
+	// 20:00 UTC is 13:00 in Los Angeles
+	if !s.Allow("u1", "A", time.Date(2025, 6, 17, 20, 0, 0, 0, time.UTC)) {
+		t.Error("Notification outside quiet hours should be allowed")
+	}
+
+	if err := s.SetSettings("u1", Settings{Timezone: "Mars/Olympus"}); err == nil {
+		t.Error("Unknown timezone should be rejected")
+	}
+}